@@ -12,13 +12,23 @@ package workspace
 //======================================================================================================================
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
 )
 
 //======================================================================================================================
@@ -36,8 +46,10 @@ const (
 	// Windows the cache directory is derived from '%LocalAppData%'.
 	Cache DirType = iota + 1
 
-	// Config is the directory containing the main application configuration file, if any. It is derived from
-	// viper.ConfigFileUsed().
+	// Config is the OS's user-specific config directory. On Unix, this is either '$XDG_CONFIG_HOME' or
+	// '$HOME/.config'. On macOS, this is '$HOME/Library/Application Support'. On Plan 9, the config directory is
+	// '$home/lib'. And lastly, on Windows the config directory is derived from '%AppData%'. Use WithRootConfig to
+	// resolve the config directory to the workspace root instead, as in previous versions.
 	Config
 
 	// Home is the default, fully expanded user home directory.
@@ -54,6 +66,52 @@ const (
 	// The path is not guaranteed to exist. Use RecreateTempDir() to recreate the directory prior to accessing it, and
 	// use RemoveTempDir() once done.
 	Temp
+
+	// PersistentTemp is the OS-specific temp directory that is preserved between system reboots. On Unix and macOS,
+	// the path is set to '/var/tmp'. On other systems, or when '/var/tmp' is not available, the directory is a
+	// sibling of the Temp directory with the suffix '-persistent'.
+	PersistentTemp
+)
+
+// Defines a pseudo enumeration of possible alias forms.
+const (
+	// Bare is the alias form using the "$" sigil without braces, e.g. "$CACHE".
+	Bare AliasForm = iota + 1
+
+	// Braced is the alias form using the "$" sigil with braces, e.g. "${CACHE}".
+	Braced
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Public Variables
+//======================================================================================================================
+
+var (
+	// ErrConfigNotFound is returned when no configuration file of a supported format can be found.
+	ErrConfigNotFound = errors.New("cannot find configuration file")
+
+	// ErrDirTypeExists is returned when a custom directory type is registered with the name of an existing type.
+	ErrDirTypeExists = errors.New("directory type already exists")
+
+	// ErrInvalidAlias is returned when an alias does not match any of the recognized forms, such as "$NAME".
+	ErrInvalidAlias = errors.New("invalid alias")
+
+	// ErrInvalidAppName is returned when an application name is empty, or contains path separators or references to a
+	// parent directory, which could escape the intended base directory.
+	ErrInvalidAppName = errors.New("invalid application name")
+
+	// ErrInvalidPath is returned when a path is not well-formed for the targeted operating system.
+	ErrInvalidPath = errors.New("invalid path")
+
+	// ErrRelativePath is returned when a relative path is provided where an absolute path is expected.
+	ErrRelativePath = errors.New("cannot process relative path")
+
+	// ErrUnknownKeyword is returned when a path references a keyword that is not associated with any directory.
+	ErrUnknownKeyword = errors.New("unknown keyword")
 )
 
 //======================================================================================================================
@@ -65,11 +123,61 @@ const (
 //======================================================================================================================
 
 var (
-	defaultCache     = []string{"$CACHE", "${CACHE}"}
-	defaultConfig    = []string{}
-	defaultHome      = []string{"$HOME", "${HOME}"}
-	defaultTemp      = []string{"$TEMP", "${TEMP}", "$TMP", "${TMP}", "$TMPDIR", "${TMPDIR}", "$TEMPDIR", "${TEMPDIR}"}
-	defaultWorkspace = []string{"$workspaceRoot", "${workspaceRoot}", "$PWD", "${PWD}"}
+	defaultCache          = []string{"$CACHE", "${CACHE}"}
+	defaultConfig         = []string{}
+	defaultHome           = []string{"$HOME", "${HOME}"}
+	defaultPersistentTemp = []string{"$PERSISTENT_TEMP", "${PERSISTENT_TEMP}"}
+	defaultWorkspace      = []string{"$workspaceRoot", "${workspaceRoot}", "$PWD", "${PWD}"}
+	defaultTemp           = []string{
+		"$TEMP", "${TEMP}", "$TMP", "${TMP}", "$TMPDIR", "${TMPDIR}", "$TEMPDIR", "${TEMPDIR}",
+	}
+
+	// varTmpDir is the directory for temporary files that are preserved between system reboots on Unix and macOS.
+	varTmpDir = "/var/tmp"
+
+	// userCacheDir resolves the user-specific cache directory, it can be replaced to simulate resolution failures.
+	userCacheDir = os.UserCacheDir
+
+	// userConfigDir resolves the user-specific config directory, it can be replaced to simulate resolution failures.
+	userConfigDir = os.UserConfigDir
+
+	// statFile retrieves the file info of a workspace marker, it can be replaced to observe filesystem access.
+	statFile = os.Stat
+
+	// rootCache holds the results of Root keyed by working directory, command, and application name, guarded by
+	// rootMutex.
+	rootCache = map[string]string{}
+	rootMutex sync.Mutex
+
+	// defaultModes defines the default permissions of each directory type. Temp directories are only accessible by the
+	// current user, whereas the other directories are readable by all users.
+	defaultModes = map[DirType]os.FileMode{
+		Cache:          0755,
+		Config:         0755,
+		Home:           0755,
+		Workspace:      0755,
+		Temp:           0700,
+		PersistentTemp: 0700,
+	}
+
+	// dirTypes holds all supported directory types in enumeration order, including the registered custom types.
+	dirTypes = []DirType{Cache, Config, Home, Workspace, Temp, PersistentTemp}
+
+	// dirTypeNames holds the names of the built-in directory types in enumeration order.
+	dirTypeNames = [...]string{"cache", "config", "home", "workspace", "temp", "persistent-temp"}
+
+	// windowsReservedNames holds the device names that cannot be used as file name on Windows, with or without an
+	// extension.
+	windowsReservedNames = []string{
+		"CON", "PRN", "AUX", "NUL",
+		"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+		"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+	}
+
+	// customTypes holds the custom directory types registered at runtime, guarded by registryMutex.
+	customTypes   = map[DirType]customType{}
+	nextDirType   = PersistentTemp + 1
+	registryMutex sync.RWMutex
 )
 
 //======================================================================================================================
@@ -80,6 +188,18 @@ var (
 // region Private Types
 //======================================================================================================================
 
+// customType defines a directory type registered at runtime.
+type customType struct {
+	name     string
+	resolver func(appName string) (string, error)
+	aliases  []string
+}
+
+// aliasPrefixOption associates a prefix for the aliases of a new application directory.
+type aliasPrefixOption struct {
+	Prefix string
+}
+
 // aliasesOption associates specific aliases for initialization of a new application directory.
 type aliasesOption struct {
 	Aliases []string
@@ -90,10 +210,41 @@ type pathOption struct {
 	Path string
 }
 
+// rootConfigOption resolves the default config directory to the workspace root.
+type rootConfigOption struct{}
+
+// relativePathOption associates a path relative to the workspace root for initialization of a new application
+// directory.
+type relativePathOption struct {
+	Path string
+}
+
+// copyOptions defines the optional arguments when copying an application directory.
+type copyOptions struct {
+	symlinks bool
+}
+
+// symlinksOption instructs CopyTo to copy symbolic links as links.
+type symlinksOption struct{}
+
+// nestedNameOption allows subdirectory-style application names for initialization of a new application directory.
+type nestedNameOption struct{}
+
+// schemaEntry describes a directory type within the JSON document returned by DirSchema.
+type schemaEntry struct {
+	Name    string   `json:"name"`
+	Pattern string   `json:"pattern,omitempty"`
+	Aliases []string `json:"aliases"`
+}
+
 // options defines the optional arguments when creating a new application directory.
 type options struct {
-	path    string
-	aliases []string
+	path       string
+	relative   bool
+	aliases    []string
+	prefix     string
+	nested     bool
+	rootConfig bool
 }
 
 //======================================================================================================================
@@ -104,9 +255,12 @@ type options struct {
 // region Public Types
 //======================================================================================================================
 
+// AliasForm defines the canonical form of an alias, either Bare or Braced.
+type AliasForm int
+
 // Dir holds a reference to a specific application directory and it's aliases (keywords).
 type Dir struct {
-	// dirType indicates the type of directory, either Cache, Config, Home, Workspace, or Temp.
+	// dirType indicates the type of directory, either Cache, Config, Home, Workspace, Temp, or PersistentTemp.
 	dirType DirType
 
 	// path is the absolute path associated with the directory.
@@ -119,6 +273,11 @@ type Dir struct {
 // DirType defines the type of directory to be configured.
 type DirType int
 
+// CopyOption defines an optional argument for copying an application directory.
+type CopyOption interface {
+	applyCopy(*copyOptions)
+}
+
 // Option defines an optional argument for creating new application directory.
 type Option interface {
 	apply(*options)
@@ -132,16 +291,242 @@ type Option interface {
 // region Private Functions
 //======================================================================================================================
 
+// apply associates an optional alias prefix for initialization of a new application directory.
+func (o aliasPrefixOption) apply(opts *options) {
+	opts.prefix = o.Prefix
+}
+
 // apply associates an optional path for initialization of a new application directory.
 func (o aliasesOption) apply(opts *options) {
 	opts.aliases = o.Aliases
 }
 
+// apply allows subdirectory-style application names for initialization of a new application directory.
+func (o nestedNameOption) apply(opts *options) {
+	opts.nested = true
+}
+
 // apply associates an optional path for initialization of a new application directory.
 func (o pathOption) apply(opts *options) {
 	opts.path = o.Path
 }
 
+// apply associates a path relative to the workspace root for initialization of a new application directory.
+func (o relativePathOption) apply(opts *options) {
+	opts.path = o.Path
+	opts.relative = true
+}
+
+// apply resolves the default config directory to the workspace root.
+func (o rootConfigOption) apply(opts *options) {
+	opts.rootConfig = true
+}
+
+// applyCopy instructs to copy symbolic links as links.
+func (o symlinksOption) applyCopy(opts *copyOptions) {
+	opts.symlinks = true
+}
+
+// aliasName returns the name of an alias without its sigil, e.g. "CACHE" for "$CACHE", "${CACHE}", and "%CACHE%".
+// The alias is returned as is when no sigil is recognized.
+func aliasName(alias string) string {
+	switch {
+	case strings.HasPrefix(alias, "${") && strings.HasSuffix(alias, "}"):
+		return alias[2 : len(alias)-1]
+	case strings.HasPrefix(alias, "$"):
+		return alias[1:]
+	case len(alias) > 2 && strings.HasPrefix(alias, "%") && strings.HasSuffix(alias, "%"):
+		return alias[1 : len(alias)-1]
+	}
+	return alias
+}
+
+// bareAlias returns the bare form of an alias within a collection of aliases, e.g. "$CACHE" for "${CACHE}". The alias
+// is returned as is if it is bare already. If the bare form of the same name is not part of the collection, the first
+// bare alias of the collection is returned instead. The alias itself is returned when no bare alias is available.
+func bareAlias(alias string, aliases []string) string {
+	if isBareAlias(alias) {
+		return alias
+	}
+	if bare := "$" + aliasName(alias); exists(aliases, bare) {
+		return bare
+	}
+	for _, a := range aliases {
+		if isBareAlias(a) {
+			return a
+		}
+	}
+	return alias
+}
+
+// cacheDir returns the base cache directory for the provided operating system. On Plan 9, the cache directory is
+// '$home/lib/cache'. Other operating systems use the user-specific cache directory provided by os.UserCacheDir().
+func cacheDir(goos string) (string, error) {
+	if goos == "plan9" {
+		home := os.Getenv("home")
+		if home == "" {
+			return "", errors.New("$home is not defined")
+		}
+		return filepath.Join(home, "lib", "cache"), nil
+	}
+	return userCacheDir()
+}
+
+// cacheDirFor returns the base cache directory of a user with the given home directory for the provided operating
+// system. It follows the conventions of os.UserCacheDir(), without consulting the environment of the current process.
+func cacheDirFor(goos string, home string) string {
+	switch goos {
+	case "windows":
+		return filepath.Join(home, "AppData", "Local")
+	case "darwin", "ios":
+		return filepath.Join(home, "Library", "Caches")
+	case "plan9":
+		return filepath.Join(home, "lib", "cache")
+	}
+	return filepath.Join(home, ".cache")
+}
+
+// configDirFor returns the base config directory of a user with the given home directory for the provided operating
+// system. It follows the conventions of os.UserConfigDir(), without consulting the environment of the current process.
+func configDirFor(goos string, home string) string {
+	switch goos {
+	case "windows":
+		return filepath.Join(home, "AppData", "Roaming")
+	case "darwin", "ios":
+		return filepath.Join(home, "Library", "Application Support")
+	case "plan9":
+		return filepath.Join(home, "lib")
+	}
+	return filepath.Join(home, ".config")
+}
+
+// copyFile copies the contents of a regular file to a new file with the provided mode.
+func copyFile(src string, dst string, mode os.FileMode) (err error) {
+	in, e := os.Open(src)
+	if e != nil {
+		return e
+	}
+	defer func() { _ = in.Close() }()
+
+	out, e := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if e != nil {
+		return e
+	}
+	defer func() {
+		if e := out.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// defaultAliases returns the default aliases of a directory type, including the aliases of registered custom types.
+// The returned slice is shared and should not be modified.
+func defaultAliases(dirType DirType) []string {
+	switch dirType {
+	case Cache:
+		return defaultCache
+	case Config:
+		return defaultConfig
+	case Workspace:
+		return defaultWorkspace
+	case Home:
+		return defaultHome
+	case Temp:
+		return defaultTemp
+	case PersistentTemp:
+		return defaultPersistentTemp
+	}
+	if c, found := lookupCustomType(dirType); found {
+		return c.aliases
+	}
+	return nil
+}
+
+// defaultPattern returns the default location of a directory type for the provided operating system, expressed as a
+// path pattern with variables, such as "${XDG_CACHE_HOME:-$HOME/.cache}/$APP_NAME". The pattern is derived from the
+// same conventions as the resolvers of NewDir. It returns an empty string for custom directory types, as their
+// location is defined by a resolver.
+func defaultPattern(goos string, dirType DirType) string {
+	home := homeVar(goos)
+	temp := "/tmp"
+	if vars := tempVars(goos); len(vars) > 0 {
+		temp = envPattern(goos, vars[0], temp)
+	}
+
+	var pattern string
+	switch dirType {
+	case Cache:
+		pattern = path.Join(envPattern(goos, userDirVar(goos, Cache), cacheDirFor(goos, home)), "$APP_NAME")
+	case Config:
+		pattern = path.Join(envPattern(goos, userDirVar(goos, Config), configDirFor(goos, home)), "$APP_NAME")
+	case Home:
+		pattern = home
+	case Workspace:
+		switch goos {
+		case "windows":
+			pattern = "%cd%"
+		case "plan9":
+			pattern = "$pwd"
+		default:
+			pattern = "$PWD"
+		}
+	case Temp:
+		pattern = path.Join(temp, "$APP_NAME")
+	case PersistentTemp:
+		if usesVarTmp(goos) {
+			pattern = path.Join(filepath.ToSlash(varTmpDir), "$APP_NAME")
+		} else {
+			pattern = path.Join(temp, "$APP_NAME-persistent")
+		}
+	default:
+		return ""
+	}
+
+	pattern = filepath.ToSlash(pattern)
+	if goos == "windows" {
+		pattern = strings.ReplaceAll(pattern, "/", `\`)
+	}
+	return pattern
+}
+
+// envPattern returns a path pattern for a directory defined by an environment variable, using the notation of the
+// provided operating system. On Unix-like systems, the fallback is included as default value, such as
+// "${XDG_CACHE_HOME:-$HOME/.cache}". On Windows, the variable is returned as is, e.g. "%LocalAppData%". The fallback is
+// returned if no variable is provided.
+func envPattern(goos string, name string, fallback string) string {
+	switch {
+	case name == "":
+		return fallback
+	case goos == "windows":
+		return "%" + name + "%"
+	}
+	return "${" + name + ":-" + filepath.ToSlash(fallback) + "}"
+}
+
+// existingAncestor returns the deepest existing path for a given path, which may be the path itself. An error is
+// returned if no existing ancestor can be found, or if the existence of a path cannot be verified.
+func existingAncestor(path string) (string, error) {
+	current := path
+	for {
+		_, e := os.Stat(current)
+		if e == nil {
+			return current, nil
+		}
+		if !os.IsNotExist(e) {
+			return "", fmt.Errorf("cannot verify path: %w", e)
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", fmt.Errorf("cannot find existing ancestor: %s", path)
+		}
+		current = parent
+	}
+}
+
 // exists validates if a specific item exists within an array.
 func exists(arr []string, item string) bool {
 	for _, a := range arr {
@@ -152,6 +537,348 @@ func exists(arr []string, item string) bool {
 	return false
 }
 
+// hashFile writes the contents of the file at path to w.
+func hashFile(w io.Writer, path string) (err error) {
+	f, e := os.Open(path)
+	if e != nil {
+		return e
+	}
+	defer func() {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// homeVar returns the variable defining the home directory of the user for the provided operating system, as used by
+// os.UserHomeDir().
+func homeVar(goos string) string {
+	switch goos {
+	case "windows":
+		return "%USERPROFILE%"
+	case "plan9":
+		return "$home"
+	}
+	return "$HOME"
+}
+
+// isBareAlias returns true if the alias uses the bare "$" sigil without braces, such as "$CACHE".
+func isBareAlias(alias string) bool {
+	return len(alias) > 1 && strings.HasPrefix(alias, "$") && !strings.HasPrefix(alias, "${")
+}
+
+// isValidAlias returns true if the alias matches one of the recognized forms "$NAME", "${NAME}", "%NAME%", or "~". The
+// name consists of letters, digits, and underscores, and may not start with a digit. The "$NAME" and "${NAME}" forms
+// may include a qualifier separated by a colon, such as "$workspaceRoot:name".
+func isValidAlias(alias string) bool {
+	if alias == "~" {
+		return true
+	}
+	name := aliasName(alias)
+	if name == alias {
+		return false
+	}
+	if i := strings.Index(name, ":"); i >= 0 && strings.HasPrefix(alias, "$") {
+		qualifier := name[i+1:]
+		if qualifier == "" || strings.ContainsAny(qualifier, `/\${}%`) {
+			return false
+		}
+		name = name[:i]
+	}
+	return isVarName(name)
+}
+
+// normalizePath calls filepath.Clean on a non-empty path, which removes any trailing separator unless the path
+// represents the root directory. An empty path is returned as is.
+func normalizePath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Clean(path)
+}
+
+// lookupCustomType retrieves a registered custom directory type.
+func lookupCustomType(dirType DirType) (customType, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	c, found := customTypes[dirType]
+	return c, found
+}
+
+// parseDirType returns the directory type with the given name, as returned by DirType.String(). It returns false if
+// no directory type with the name is registered.
+func parseDirType(name string) (DirType, bool) {
+	for _, t := range registeredDirTypes() {
+		if t.String() == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
+
+// persistentTempPath returns the application's persistent temp directory for the provided operating system. On Unix
+// and macOS, the directory is located in '/var/tmp' if available. Otherwise, the directory is a sibling of the
+// application's temp directory with the suffix '-persistent', ensuring both directories remain distinct.
+func persistentTempPath(goos string, appName string) string {
+	if usesVarTmp(goos) {
+		if info, e := os.Stat(varTmpDir); e == nil && info.IsDir() {
+			return filepath.Join(varTmpDir, appName)
+		}
+	}
+	return filepath.Join(tempDir(goos), appName+"-persistent")
+}
+
+// preferAlias returns whether alias a is preferred over alias b as canonical alias. A bare alias is preferred over
+// other forms, followed by the shortest alias, followed by the alias that sorts first alphabetically.
+func preferAlias(a string, b string) bool {
+	bareA, bareB := isBareAlias(a), isBareAlias(b)
+	switch {
+	case bareA != bareB:
+		return bareA
+	case len(a) != len(b):
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+// prefixAliases returns a copy of the aliases with the prefix inserted after the sigil of each alias, e.g.
+// "$plugin.CACHE" for "$CACHE" and "${plugin.CACHE}" for "${CACHE}". Aliases without a recognized sigil, such as "~",
+// are omitted, as they cannot be namespaced.
+func prefixAliases(aliases []string, prefix string) []string {
+	prefixed := make([]string, 0, len(aliases))
+	for _, a := range aliases {
+		name := aliasName(a)
+		if name == a {
+			continue
+		}
+		prefixed = append(prefixed, strings.Replace(a, name, prefix+name, 1))
+	}
+	return prefixed
+}
+
+// registeredDirTypes returns all supported directory types in enumeration order, including the registered custom
+// types.
+func registeredDirTypes() []DirType {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	types := make([]DirType, len(dirTypes))
+	copy(types, dirTypes)
+	return types
+}
+
+// tempDir returns the base temp directory for the provided operating system. On Plan 9, the temp directory is '/tmp'.
+// Other operating systems use the directory provided by os.TempDir().
+func tempDir(goos string) string {
+	if goos == "plan9" {
+		return "/tmp"
+	}
+	return os.TempDir()
+}
+
+// tempSource returns the base temp directory for the provided operating system, similar to tempDir, and the source
+// that provided it. The source is the name of the environment variable defining the directory, or the path itself
+// when the system default is used. The variables are checked in the same order as os.TempDir(), e.g. TMPDIR on Unix
+// and TMP, TEMP, and USERPROFILE on Windows.
+func tempSource(goos string) (path string, source string) {
+	path = tempDir(goos)
+	for _, v := range tempVars(goos) {
+		if os.Getenv(v) != "" {
+			return path, v
+		}
+	}
+	return path, path
+}
+
+// tempVars returns the environment variables defining the base temp directory for the provided operating system, in
+// the order checked by os.TempDir().
+func tempVars(goos string) []string {
+	switch goos {
+	case "plan9":
+		return nil
+	case "windows":
+		return []string{"TMP", "TEMP", "USERPROFILE"}
+	}
+	return []string{"TMPDIR"}
+}
+
+// unregisterDirType removes a custom directory type registered with RegisterDirType. It allows tests to restore the
+// registry, as registered types cannot be removed otherwise. Built-in types are not removed.
+func unregisterDirType(dirType DirType) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, found := customTypes[dirType]; !found {
+		return
+	}
+	delete(customTypes, dirType)
+	for i, t := range dirTypes {
+		if t == dirType {
+			dirTypes = append(dirTypes[:i:i], dirTypes[i+1:]...)
+			break
+		}
+	}
+}
+
+// userDirVar returns the environment variable defining the base cache or config directory for the provided operating
+// system, as used by os.UserCacheDir() and os.UserConfigDir(). It returns an empty string if the directory is derived
+// from the home directory only, such as on macOS and Plan 9.
+func userDirVar(goos string, dirType DirType) string {
+	switch goos {
+	case "darwin", "ios", "plan9":
+		return ""
+	case "windows":
+		if dirType == Cache {
+			return "LocalAppData"
+		}
+		return "AppData"
+	}
+	if dirType == Cache {
+		return "XDG_CACHE_HOME"
+	}
+	return "XDG_CONFIG_HOME"
+}
+
+// usesVarTmp returns whether the provided operating system supports a temp directory that is preserved between system
+// reboots, such as '/var/tmp' on Unix and macOS.
+func usesVarTmp(goos string) bool {
+	switch goos {
+	case "plan9", "windows", "js", "wasip1":
+		return false
+	}
+	return true
+}
+
+// validateAppName validates an application name is safe to join with a base directory. The name may not contain path
+// separators, unless nested is set. In that case, each path element of the name is validated instead. Elements
+// referring to the current or parent directory are rejected.
+func validateAppName(appName string, nested bool) error {
+	if strings.TrimSpace(appName) == "" {
+		return fmt.Errorf("%w: name is empty", ErrInvalidAppName)
+	}
+
+	elements := []string{appName}
+	if nested {
+		elements = strings.FieldsFunc(appName, func(r rune) bool { return r == '/' || r == os.PathSeparator })
+		if strings.HasPrefix(appName, "/") || strings.HasPrefix(appName, string(os.PathSeparator)) {
+			return fmt.Errorf("%w: %s", ErrInvalidAppName, appName)
+		}
+	}
+
+	for _, e := range elements {
+		if e == "." || e == ".." || strings.ContainsAny(e, "/"+string(os.PathSeparator)) {
+			return fmt.Errorf("%w: %s", ErrInvalidAppName, appName)
+		}
+	}
+	return nil
+}
+
+// validateWindowsPath validates a path is well-formed on Windows, see ValidatePathFor. Both forward slashes and
+// backslashes are considered path separators. The length of the path is not limited for extended-length paths
+// prefixed with '\\?\'.
+func validateWindowsPath(path string) error {
+	// strip the extended-length prefix and the drive letter
+	extended := strings.HasPrefix(path, `\\?\`)
+	if extended {
+		path = path[4:]
+	}
+	if !extended && utf8.RuneCountInString(path) > 259 {
+		return fmt.Errorf("%w: path exceeds 259 characters", ErrInvalidPath)
+	}
+	if len(path) >= 2 && path[1] == ':' && unicode.IsLetter(rune(path[0])) {
+		path = path[2:]
+	}
+
+	elements := strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '\\' })
+	for _, elem := range elements {
+		if elem == "." || elem == ".." {
+			continue
+		}
+		if utf8.RuneCountInString(elem) > 255 {
+			return fmt.Errorf("%w: element exceeds 255 characters: %s", ErrInvalidPath, elem)
+		}
+		if i := strings.IndexFunc(elem, func(r rune) bool { return r < 32 || strings.ContainsRune(`<>:"|?*`, r) }); i >= 0 {
+			return fmt.Errorf("%w: illegal character %q: %s", ErrInvalidPath, elem[i], elem)
+		}
+		if strings.HasSuffix(elem, " ") || strings.HasSuffix(elem, ".") {
+			return fmt.Errorf("%w: element ends with space or period: %s", ErrInvalidPath, elem)
+		}
+
+		name := strings.ToUpper(elem)
+		if i := strings.Index(name, "."); i >= 0 {
+			name = name[:i]
+		}
+		if exists(windowsReservedNames, strings.TrimRight(name, " ")) {
+			return fmt.Errorf("%w: reserved name: %s", ErrInvalidPath, elem)
+		}
+	}
+
+	return nil
+}
+
+// walkTree walks the file tree rooted at root in lexical order, calling fn for each file or directory similar to
+// filepath.Walk. Symbolic links to directories are traversed if follow is set. Each directory is visited at most once,
+// identified by its device and inode on Unix and by its resolved path elsewhere, which ensures the walk terminates for
+// symbolic links pointing to an ancestor directory.
+func walkTree(root string, follow bool, fn filepath.WalkFunc) error {
+	info, err := os.Lstat(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	err = walkPath(root, info, follow, make(map[string]bool), fn)
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+// walkPath recursively walks the file or directory at path, see walkTree. Directories that were visited already are
+// skipped.
+func walkPath(path string, info os.FileInfo, follow bool, visited map[string]bool, fn filepath.WalkFunc) error {
+	if follow && info.Mode()&os.ModeSymlink != 0 {
+		if target, e := os.Stat(path); e == nil && target.IsDir() {
+			info = target
+		}
+	}
+	if !info.IsDir() {
+		return fn(path, info, nil)
+	}
+
+	// skip directories that were visited already, e.g. via a symbolic link
+	key := fileKey(path, info)
+	if visited[key] {
+		return nil
+	}
+	visited[key] = true
+
+	if err := fn(path, info, nil); err != nil {
+		if err == filepath.SkipDir {
+			return nil
+		}
+		return err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return fn(path, info, err)
+	}
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		childInfo, err := os.Lstat(child)
+		if err != nil {
+			err = fn(child, nil, err)
+		} else {
+			err = walkPath(child, childInfo, follow, visited, fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 //======================================================================================================================
 // endregion
 //======================================================================================================================
@@ -163,7 +890,11 @@ func exists(arr []string, item string) bool {
 // NewDir creates a new Dir instance for the provided arguments. NewDir supports two optional parameters, set by
 // WithAliases and WithPath respectively. WithAliases associates specific aliases with the application directory.
 // WithPath initializes the application directory for a specific path. If omitted, both parameters revert to a default
-// value pending the dir type.
+// value pending the dir type. An error wrapping ErrRelativePath is returned when WithPath provides a relative path, use
+// WithRelativePath to resolve a relative path against the workspace root instead.
+// The appName may not contain path separators or references to a parent directory, an error wrapping
+// ErrInvalidAppName is returned otherwise. Use WithNestedName to allow subdirectory-style names, such as
+// "vendor/product".
 func NewDir(dirType DirType, appName string, opts ...Option) (dir *Dir, err error) {
 	// init the options
 	options := options{}
@@ -172,25 +903,57 @@ func NewDir(dirType DirType, appName string, opts ...Option) (dir *Dir, err erro
 		o.apply(&options)
 	}
 
+	// validate the application name
+	if e := validateAppName(appName, options.nested); e != nil {
+		return nil, e
+	}
+
 	// init the path
 	if options.path != "" {
 		if !filepath.IsAbs(options.path) {
-			return nil, fmt.Errorf("cannot process relative path: %s", options.path)
+			if !options.relative {
+				return nil, fmt.Errorf("%w: %s", ErrRelativePath, options.path)
+			}
+			// resolve the relative path against the workspace root
+			root, e := Root(filepath.Base(appName))
+			if e != nil {
+				return nil, fmt.Errorf("cannot initialize directory: %s: %w", dirType.String(), e)
+			}
+			options.path = filepath.Join(root, options.path)
 		}
 	} else {
 		switch dirType {
 		case Cache:
-			options.path, err = os.UserCacheDir()
+			options.path, err = cacheDir(runtime.GOOS)
+			options.path = filepath.Join(options.path, appName)
+
+		case Config:
+			if options.rootConfig {
+				// match the binary's name against the last element of a nested application name
+				options.path, err = Root(filepath.Base(appName))
+				break
+			}
+			// the user-specific config directory, e.g. '$HOME/.config/app' on Unix
+			options.path, err = userConfigDir()
 			options.path = filepath.Join(options.path, appName)
 
-		case Config, Workspace:
-			options.path, err = Root(appName)
+		case Workspace:
+			// match the binary's name against the last element of a nested application name
+			options.path, err = Root(filepath.Base(appName))
 
 		case Home:
 			options.path, err = os.UserHomeDir()
 
 		case Temp:
-			options.path = filepath.Join(os.TempDir(), appName)
+			options.path = filepath.Join(tempDir(runtime.GOOS), appName)
+
+		case PersistentTemp:
+			options.path = persistentTempPath(runtime.GOOS, appName)
+
+		default:
+			if c, found := lookupCustomType(dirType); found {
+				options.path, err = c.resolver(appName)
+			}
 		}
 	}
 	if err != nil {
@@ -199,28 +962,16 @@ func NewDir(dirType DirType, appName string, opts ...Option) (dir *Dir, err erro
 
 	// init the aliases
 	if len(options.aliases) == 0 {
-		switch dirType {
-		case Cache:
-			options.aliases = defaultCache
-
-		case Config:
-			options.aliases = defaultConfig
-
-		case Workspace:
-			options.aliases = defaultWorkspace
-
-		case Home:
-			options.aliases = defaultHome
-
-		case Temp:
-			options.aliases = defaultTemp
-		}
+		options.aliases = defaultAliases(dirType)
+	}
+	if options.prefix != "" {
+		options.aliases = prefixAliases(options.aliases, options.prefix)
 	}
 
 	// create a new Dir and return the value
 	dir = &Dir{
 		dirType: dirType,
-		path:    filepath.Clean(options.path),
+		path:    normalizePath(options.path),
 		aliases: options.aliases,
 	}
 
@@ -247,16 +998,275 @@ func (d *Dir) AppendAliases(aliases ...string) {
 	sort.Strings(d.aliases)
 }
 
-// DirType retrieves the type of configured directory, either Cache, Config, Home, Workspace, or Temp.
+// AppendAliasesStrict appends one or more aliases similar to AppendAliases, after validating each alias matches one of
+// the recognized forms "$NAME", "${NAME}", "%NAME%", or "~". Aliases of another form are never matched by
+// AppDirs.MakeAbsolute. An error wrapping ErrInvalidAlias is returned if any of the aliases is invalid, in which case
+// none of the aliases is appended.
+func (d *Dir) AppendAliasesStrict(aliases ...string) error {
+	for _, a := range aliases {
+		if !isValidAlias(a) {
+			return fmt.Errorf("%w: %s", ErrInvalidAlias, a)
+		}
+	}
+	d.AppendAliases(aliases...)
+	return nil
+}
+
+// AvailableBytes returns the free space available to the current user on the filesystem containing the directory. The
+// nearest existing ancestor is used when the directory does not exist yet. AvailableBytes is supported on Linux, macOS,
+// FreeBSD, DragonFly BSD, and Windows, an error is returned on other platforms.
+func (d *Dir) AvailableBytes() (uint64, error) {
+	path, e := existingAncestor(d.Path())
+	if e != nil {
+		return 0, e
+	}
+
+	available, e := availableBytes(path)
+	if e != nil {
+		return 0, fmt.Errorf("cannot determine available space: %w", e)
+	}
+	return available, nil
+}
+
+// CanonicalAlias returns the alias used to parameterize the path of the directory, e.g. by AppDirs.Parameterize. The
+// canonical alias is the first default alias of the directory type that is associated with the directory (see
+// DefaultAliases). Otherwise, the shortest alias in bare form (e.g. "$CACHE") is used, or the shortest alias if no bare
+// alias is available. Ties are resolved alphabetically, so the result does not depend on the order of the aliases. It
+// returns an empty string if the directory has no aliases.
+func (d *Dir) CanonicalAlias() string {
+	for _, a := range defaultAliases(d.dirType) {
+		if exists(d.aliases, a) {
+			return a
+		}
+	}
+
+	var canonical string
+	for _, a := range d.aliases {
+		if canonical == "" || preferAlias(a, canonical) {
+			canonical = a
+		}
+	}
+	return canonical
+}
+
+// Chmod changes the mode of the directory to mode. If recursive is set, the mode of all files and subdirectories within
+// the directory is changed too. Symbolic links are not followed and their targets are left untouched, which ensures
+// Chmod does not modify any files outside of the directory. The descendants are updated before their parents, so a
+// restrictive mode does not prevent the traversal of the directory tree.
+func (d *Dir) Chmod(mode os.FileMode, recursive bool) error {
+	// collect the paths to update in lexical order, ensuring parents precede their children
+	var paths []string
+	err := walkTree(d.path, false, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		paths = append(paths, path)
+		if !recursive && info.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot change mode of directory: %w", err)
+	}
+
+	// update the paths in reverse order
+	for i := len(paths) - 1; i >= 0; i-- {
+		if e := os.Chmod(paths[i], mode); e != nil {
+			return fmt.Errorf("cannot change mode of directory: %w", e)
+		}
+	}
+
+	return nil
+}
+
+// CopyTo recursively copies the files and subdirectories of the directory to dest, preserving their modes. The
+// destination is created if needed and may not be located within the directory itself. Symbolic links are skipped,
+// unless WithSymlinks is provided to copy them as links. Other irregular files, such as devices and sockets, are
+// skipped too.
+func (d *Dir) CopyTo(dest string, opts ...CopyOption) error {
+	options := copyOptions{}
+	for _, o := range opts {
+		o.applyCopy(&options)
+	}
+
+	src := d.Path()
+	dest = filepath.Clean(dest)
+	if _, ok := relWithin(src, dest); ok {
+		return fmt.Errorf("cannot copy directory into itself: %s", dest)
+	}
+
+	// copy the files and create the directories, the modes of the directories are applied once all files are copied
+	type dirMode struct {
+		path string
+		mode os.FileMode
+	}
+	var dirs []dirMode
+	e := walkTree(src, false, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, e := filepath.Rel(src, path)
+		if e != nil {
+			return e
+		}
+		target := filepath.Join(dest, rel)
+
+		switch mode := info.Mode(); {
+		case mode.IsDir():
+			dirs = append(dirs, dirMode{path: target, mode: mode.Perm()})
+			return os.MkdirAll(target, 0700)
+		case mode&os.ModeSymlink != 0:
+			if !options.symlinks {
+				return nil
+			}
+			link, e := os.Readlink(path)
+			if e != nil {
+				return e
+			}
+			return os.Symlink(link, target)
+		case mode.IsRegular():
+			if e := copyFile(path, target, mode.Perm()); e != nil {
+				return e
+			}
+			return os.Chmod(target, mode.Perm())
+		}
+		return nil
+	})
+	if e != nil {
+		return fmt.Errorf("cannot copy directory: %w", e)
+	}
+
+	// apply the directory modes in reverse order, to ensure read-only directories are applied last
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if e := os.Chmod(dirs[i].path, dirs[i].mode); e != nil {
+			return fmt.Errorf("cannot copy directory: %w", e)
+		}
+	}
+
+	return nil
+}
+
+// DirType retrieves the type of configured directory, either Cache, Config, Home, Workspace, Temp, or PersistentTemp.
 func (d *Dir) DirType() DirType {
 	return d.dirType
 }
 
+// Hash returns a SHA-256 digest of the contents of the directory, formatted as hexadecimal string. The directory tree
+// is walked in lexical order, hashing the relative path and type of each entry and the contents of each file. Symbolic
+// links are not followed, the path of the link and its target path are hashed instead. The digest is stable as long as
+// the names and contents within the directory remain the same, regardless of modification times and permissions.
+func (d *Dir) Hash() (string, error) {
+	h := sha256.New()
+	e := walkTree(d.path, false, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, e := filepath.Rel(d.path, path)
+		if e != nil {
+			return e
+		}
+		rel = filepath.ToSlash(rel)
+
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			target, e := os.Readlink(path)
+			if e != nil {
+				return e
+			}
+			fmt.Fprintf(h, "l %s\x00%s\x00", rel, filepath.ToSlash(target))
+		case info.IsDir():
+			fmt.Fprintf(h, "d %s\x00", rel)
+		case info.Mode().IsRegular():
+			fmt.Fprintf(h, "f %s\x00%d\x00", rel, info.Size())
+			return hashFile(h, path)
+		}
+		return nil
+	})
+	if e != nil {
+		return "", fmt.Errorf("cannot hash directory: %w", e)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// IsDefault returns whether the directory is at its default location for the given application name. The default
+// location is determined by NewDir without the WithPath option. IsDefault returns an error if the default location
+// cannot be determined.
+func (d *Dir) IsDefault(appName string) (bool, error) {
+	def, err := NewDir(d.dirType, appName)
+	if err != nil {
+		return false, err
+	}
+	return d.path == def.path, nil
+}
+
+// NormalizeAliases rewrites each alias of the directory to the given form, e.g. "$CACHE", "${CACHE}", and "%CACHE%"
+// all become "${CACHE}" for the Braced form. Duplicate aliases are removed, retaining the order of first occurrence.
+// Aliases without a recognized sigil, such as "~", are kept as is.
+func (d *Dir) NormalizeAliases(form AliasForm) {
+	aliases := make([]string, 0, len(d.aliases))
+	for _, a := range d.aliases {
+		if name := aliasName(a); name != a {
+			switch form {
+			case Bare:
+				a = "$" + name
+			case Braced:
+				a = "${" + name + "}"
+			}
+		}
+		if !exists(aliases, a) {
+			aliases = append(aliases, a)
+		}
+	}
+	d.aliases = aliases
+}
+
 // Path retrieves the absolute path associated with the directory.
 func (d *Dir) Path() string {
 	return d.path
 }
 
+// PruneEmpty removes all empty subdirectories within the directory, traversing the directory tree bottom-up. A
+// subdirectory that only contains empty subdirectories is removed too. The directory itself is never removed, and
+// symbolic links are not followed. It returns the number of removed subdirectories.
+func (d *Dir) PruneEmpty() (removed int, err error) {
+	// collect the subdirectories in lexical order, ensuring parents precede their children
+	var dirs []string
+	err = walkTree(d.path, false, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && path != d.path {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("cannot prune directory: %w", err)
+	}
+
+	// remove the empty subdirectories in reverse order
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, e := os.ReadDir(dirs[i])
+		if e != nil {
+			return removed, fmt.Errorf("cannot prune directory: %w", e)
+		}
+		if len(entries) > 0 {
+			continue
+		}
+		if e := os.Remove(dirs[i]); e != nil {
+			return removed, fmt.Errorf("cannot prune directory: %w", e)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
 // RemoveAliases removes one or more aliases from the collection of aliases. Unrecognized aliases are ignored.
 func (d *Dir) RemoveAliases(aliases ...string) {
 	for _, a := range aliases {
@@ -269,24 +1279,68 @@ func (d *Dir) RemoveAliases(aliases ...string) {
 	}
 }
 
-// String converts a directory type to it's string representation.
+// Snapshot returns a fingerprint of each file within the directory and its subdirectories, keyed by the path relative
+// to the directory. The fingerprint is derived from the size and modification time of the file. Directories are not
+// included. Symbolic links are not followed, but are fingerprinted like regular files. Use DiffSnapshots to compare
+// two snapshots.
+func (d *Dir) Snapshot() (map[string]int64, error) {
+	snapshot := make(map[string]int64)
+	err := walkTree(d.path, false, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(d.path, path)
+		if err != nil {
+			return err
+		}
+
+		h := fnv.New64a()
+		fmt.Fprintf(h, "%d:%d", info.Size(), info.ModTime().UnixNano())
+		snapshot[rel] = int64(h.Sum64())
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot snapshot directory: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// DefaultMode returns the default permissions for creating a directory of the given type. Temp directories default to
+// 0700, whereas Cache, Config, Home, and Workspace directories default to 0755. Unknown types default to 0755 too.
+func (d DirType) DefaultMode() os.FileMode {
+	if mode, found := defaultModes[d]; found {
+		return mode
+	}
+	return 0755
+}
+
+// String converts a directory type to it's string representation. The registered name is returned for custom
+// directory types.
 func (d DirType) String() string {
-	if d < Cache || d > Temp {
+	if c, found := lookupCustomType(d); found {
+		return c.name
+	}
+	if d < Cache || d > PersistentTemp {
 		return ""
 	}
-	return [...]string{"cache", "config", "home", "workspace", "temp"}[d-1]
+	return dirTypeNames[d-1]
 }
 
 // AbsPath returns the absolute path for a given base path and path. If path is relative it is joined with the base
 // path, otherwise the path itself is returned. AbsPath calls filepath.Clean on the result. The special character "~"
-// is expanded to the user's home directory (if set as prefix).
+// is expanded to the user's home directory only if it is the first path component, e.g. "~" or "~/x". A "~" elsewhere
+// in the path, such as in "a/~/b" or "~backup", is kept as literal. The character is not expanded on Windows.
 func AbsPath(base string, path string) string {
-	if runtime.GOOS != "windows" && strings.HasPrefix(path, "~") {
+	if runtime.GOOS != "windows" && (path == "~" || strings.HasPrefix(path, "~"+string(os.PathSeparator))) {
 		dir, e := os.UserHomeDir()
 		if e != nil {
 			dir = "~"
 		}
-		path = strings.Replace(path, "~", dir, 1)
+		path = ExpandHomeFor(runtime.GOOS, dir, path)
 	}
 
 	if filepath.IsAbs(path) {
@@ -296,17 +1350,267 @@ func AbsPath(base string, path string) string {
 	return filepath.Clean(filepath.Join(base, path))
 }
 
+// BestTempDir returns the base temp directory used for the Temp directory type and the source that provided it. The
+// source is the name of the environment variable defining the directory, such as "TMPDIR" on Unix or "TMP" and "TEMP"
+// on Windows, or the path itself when the system default is used, such as "/tmp". An error is returned if the
+// directory does not exist or is not a directory, in which case the path and source are still reported.
+func BestTempDir() (path string, source string, err error) {
+	path, source = tempSource(runtime.GOOS)
+	info, e := os.Stat(path)
+	if e != nil {
+		return path, source, fmt.Errorf("cannot access temp directory: %w", e)
+	}
+	if !info.IsDir() {
+		return path, source, fmt.Errorf("temp directory is not a directory: %s", path)
+	}
+	return path, source, nil
+}
+
+// CacheSource returns the user-specific cache directory and reports whether the path is derived from $XDG_CACHE_HOME.
+// The variable is only honored on Unix systems other than macOS, consistent with os.UserCacheDir(). The returned path
+// does not include the application name.
+func CacheSource() (path string, fromXDG bool, err error) {
+	path, err = os.UserCacheDir()
+	if err != nil {
+		return "", false, err
+	}
+
+	switch runtime.GOOS {
+	case "darwin", "ios", "plan9", "windows":
+		return path, false, nil
+	}
+
+	xdg := os.Getenv("XDG_CACHE_HOME")
+	return path, xdg != "" && filepath.Clean(xdg) == filepath.Clean(path), nil
+}
+
+// DefaultAliases returns a copy of the default aliases (keywords) of a directory type, including the aliases of custom
+// types registered with RegisterDirType. It returns an empty slice for unknown directory types.
+func DefaultAliases(dirType DirType) []string {
+	defaults := defaultAliases(dirType)
+	aliases := make([]string, len(defaults))
+	copy(aliases, defaults)
+	return aliases
+}
+
+// DefaultPaths returns the default path of each directory type for the given application name, including custom
+// types registered with RegisterDirType. The paths are resolved by NewDir and are not assigned or created. Errors are
+// aggregated into a single error, in which case the returned map contains the paths that could be resolved.
+func DefaultPaths(appName string) (map[DirType]string, error) {
+	types := registeredDirTypes()
+	paths := make(map[DirType]string, len(types))
+	var failed []string
+	for _, t := range types {
+		d, e := NewDir(t, appName)
+		if e != nil {
+			failed = append(failed, fmt.Sprintf("%s (%s)", t.String(), e.Error()))
+			continue
+		}
+		paths[t] = d.Path()
+	}
+
+	if len(failed) > 0 {
+		return paths, fmt.Errorf("cannot resolve default paths: %s", strings.Join(failed, ", "))
+	}
+	return paths, nil
+}
+
+// DiffSnapshots compares two snapshots created by Snapshot. It returns the paths that were added, removed, or changed
+// between the before and after snapshot, each sorted alphabetically.
+func DiffSnapshots(before map[string]int64, after map[string]int64) (added, removed, changed []string) {
+	for path, fingerprint := range after {
+		if prev, found := before[path]; !found {
+			added = append(added, path)
+		} else if prev != fingerprint {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, found := after[path]; !found {
+			removed = append(removed, path)
+		}
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+	sort.Strings(changed)
+	return added, removed, changed
+}
+
+// DirSchema returns a JSON document describing each directory type, including custom types registered with
+// RegisterDirType. Each entry lists the name of the type, its default location on the current operating system as a
+// path pattern, and its default aliases. The pattern is omitted for custom types. The document is suitable for
+// generating configuration documentation or editor autocompletion. For example, the first entry on Linux:
+//
+//	{
+//	  "name": "cache",
+//	  "pattern": "${XDG_CACHE_HOME:-$HOME/.cache}/$APP_NAME",
+//	  "aliases": [
+//	    "$CACHE",
+//	    "${CACHE}"
+//	  ]
+//	}
+func DirSchema() []byte {
+	types := registeredDirTypes()
+	entries := make([]schemaEntry, 0, len(types))
+	for _, t := range types {
+		entries = append(entries, schemaEntry{
+			Name:    t.String(),
+			Pattern: defaultPattern(runtime.GOOS, t),
+			Aliases: DefaultAliases(t),
+		})
+	}
+
+	// the entries consist of strings only, so marshaling cannot fail
+	schema, _ := json.MarshalIndent(entries, "", "  ")
+	return schema
+}
+
+// ExpandHomeFor expands the special character "~" to the provided home directory, applying the rules of the provided
+// operating system. The character is only expanded if it is the first path component, e.g. "~" or "~/x". On Windows,
+// the character is not expanded and the path is returned as is.
+func ExpandHomeFor(goos string, home string, path string) string {
+	if goos == "windows" {
+		return path
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return home + path[1:]
+	}
+	return path
+}
+
+// LoadXDGUserDirs parses the XDG user directories file ('$XDG_CONFIG_HOME/user-dirs.dirs' or
+// '$HOME/.config/user-dirs.dirs') and returns the configured paths, keyed by variable name (e.g. "XDG_DOCUMENTS_DIR").
+// Values are expected to be either absolute or relative to '$HOME', as in 'XDG_DOCUMENTS_DIR="$HOME/Docs"'. An empty
+// map is returned when the file does not exist or the OS is not Linux.
+func LoadXDGUserDirs() (dirs map[string]string, err error) {
+	dirs = make(map[string]string)
+	if runtime.GOOS != "linux" {
+		return dirs, nil
+	}
+
+	config, e := os.UserConfigDir()
+	if e != nil {
+		return dirs, nil
+	}
+	f, e := os.Open(filepath.Join(config, "user-dirs.dirs"))
+	if e != nil {
+		if os.IsNotExist(e) {
+			return dirs, nil
+		}
+		return nil, fmt.Errorf("cannot read XDG user directories: %w", e)
+	}
+	defer func() {
+		if e := f.Close(); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	home, e := os.UserHomeDir()
+	if e != nil {
+		return nil, fmt.Errorf("cannot read XDG user directories: %w", e)
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i < 0 {
+			continue
+		}
+		key, value := strings.TrimSpace(line[:i]), strings.Trim(strings.TrimSpace(line[i+1:]), `"`)
+		switch {
+		case value == "$HOME":
+			value = home
+		case strings.HasPrefix(value, "$HOME/"):
+			value = filepath.Join(home, strings.TrimPrefix(value, "$HOME/"))
+		case !filepath.IsAbs(value):
+			continue
+		}
+		dirs[key] = filepath.Clean(value)
+	}
+	if e := scanner.Err(); e != nil {
+		return nil, fmt.Errorf("cannot read XDG user directories: %w", e)
+	}
+
+	return dirs, nil
+}
+
+// RegisterDirType registers a custom directory type at runtime and returns its newly allocated value. The resolver
+// determines the default path of the directory for a given application name, and the aliases define its default
+// keywords. The custom type is supported by NewDir, DirType.String, and AppDirs.Assign, which makes the package
+// extensible with application-specific directory categories. An error wrapping ErrDirTypeExists is returned if the
+// name equals the name of a built-in or previously registered type. RegisterDirType is safe for concurrent use.
+func RegisterDirType(name string, resolver func(appName string) (string, error), aliases []string) (DirType, error) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	for _, n := range dirTypeNames {
+		if n == name {
+			return 0, fmt.Errorf("%w: %s", ErrDirTypeExists, name)
+		}
+	}
+	for _, c := range customTypes {
+		if c.name == name {
+			return 0, fmt.Errorf("%w: %s", ErrDirTypeExists, name)
+		}
+	}
+
+	dirType := nextDirType
+	nextDirType++
+	a := make([]string, len(aliases))
+	copy(a, aliases)
+	customTypes[dirType] = customType{name: name, resolver: resolver, aliases: a}
+	dirTypes = append(dirTypes, dirType)
+
+	return dirType, nil
+}
+
+// ResetRootCache clears the results cached by Root, which causes the next call to Root to locate the workspace root
+// again.
+func ResetRootCache() {
+	rootMutex.Lock()
+	defer rootMutex.Unlock()
+	rootCache = map[string]string{}
+}
+
 // Root returns the working directory of the repository or the running command. In debugging mode, the current working
 // directory may actually be a sub directory, such as 'src' or 'cmd'. In these cases, the workspace root is set to the
 // nearest parent directory containing a ".git" repository. When running a compiled binary, the function returns the
-// current working directory.
+// current working directory. Root delegates to RootFromArgs using the command name and current working directory of
+// the running process. The result is cached for the current working directory, use ResetRootCache to invalidate the
+// cache, e.g. after changing the working directory of the process.
 func Root(appName string) (path string, err error) {
-	_, cmd := filepath.Split(os.Args[0])
 	dir, e := os.Getwd()
 	if e != nil {
 		return "", e
 	}
 
+	key := strings.Join([]string{dir, os.Args[0], appName}, "\x00")
+	rootMutex.Lock()
+	defer rootMutex.Unlock()
+	if path, found := rootCache[key]; found {
+		return path, nil
+	}
+
+	path, err = RootFromArgs(appName, os.Args[0], dir)
+	if err == nil {
+		rootCache[key] = path
+	}
+	return path, err
+}
+
+// RootFromArgs returns the workspace root for the provided command (typically os.Args[0]) and working directory. The
+// working directory is returned as is when the command name equals appName, which indicates a compiled binary.
+// Otherwise, the workspace root is set to the nearest parent directory of wd containing a ".git" repository. A ".git"
+// file, as used by git worktrees and submodules, marks the workspace root too.
+func RootFromArgs(appName string, arg0 string, wd string) (path string, err error) {
+	_, cmd := filepath.Split(arg0)
+	dir := wd
+
 	// return the current working directory when running a compiled binary
 	if cmd == appName {
 		return dir, nil
@@ -315,9 +1619,9 @@ func Root(appName string) (path string, err error) {
 	// traverse the current path for a workspace marker in reverse order
 	isRoot := false
 	for {
-		// return the current path if it contains a ".git" directory
-		s, err := os.Stat(filepath.Join(dir, ".git"))
-		if err == nil && s.IsDir() {
+		// return the current path if it contains a ".git" directory, or a ".git" file for worktrees and submodules
+		s, err := statFile(filepath.Join(dir, ".git"))
+		if err == nil && (s.IsDir() || s.Mode().IsRegular()) {
 			return dir, nil
 		}
 
@@ -335,16 +1639,81 @@ func Root(appName string) (path string, err error) {
 	}
 }
 
+// ValidatePathFor validates a path is well-formed for the operating system goos, regardless of the operating system
+// of the host. The path may not be empty nor contain a null character. On Windows, reserved device names such as
+// "CON" and "PRN", the characters '<', '>', ':', '"', '|', '?', and '*', control characters, and elements ending with
+// a space or period are rejected. The path is limited to 259 characters on Windows, 1023 bytes on macOS and iOS, and
+// 4095 bytes on other systems. Path elements are limited to 255 characters (or bytes on systems other than Windows).
+// An error wrapping ErrInvalidPath is returned if the path is invalid.
+func ValidatePathFor(goos string, path string) error {
+	if path == "" {
+		return fmt.Errorf("%w: path is empty", ErrInvalidPath)
+	}
+	if strings.ContainsRune(path, 0) {
+		return fmt.Errorf("%w: path contains null character", ErrInvalidPath)
+	}
+	if goos == "windows" {
+		return validateWindowsPath(path)
+	}
+
+	limit := 4095
+	if goos == "darwin" || goos == "ios" {
+		limit = 1023
+	}
+	if len(path) > limit {
+		return fmt.Errorf("%w: path exceeds %d bytes", ErrInvalidPath, limit)
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if len(elem) > 255 {
+			return fmt.Errorf("%w: element exceeds 255 bytes: %s", ErrInvalidPath, elem)
+		}
+	}
+
+	return nil
+}
+
 // WithAliases associates optional aliases to be used by the application directory. A default value is used if omitted.
 func WithAliases(aliases []string) Option {
 	return aliasesOption{Aliases: aliases}
 }
 
+// WithAliasPrefix defines a prefix for each alias of a new application directory, which is inserted after the sigil
+// of the alias. For example, the prefix "plugin." turns the alias "$CACHE" into "$plugin.CACHE". This enables multiple
+// components to share the same AppDirs without clashing keywords. Aliases without a sigil, such as "~", are omitted.
+func WithAliasPrefix(prefix string) Option {
+	return aliasPrefixOption{Prefix: prefix}
+}
+
+// WithNestedName allows subdirectory-style application names, such as "vendor/product". Each element of the name is
+// joined with the base directory. References to the current or parent directory remain invalid.
+func WithNestedName() Option {
+	return nestedNameOption{}
+}
+
 // WithPath associates an optional path to be used by the application directory. A default value is used if omitted.
 func WithPath(path string) Option {
 	return pathOption{Path: path}
 }
 
+// WithRelativePath associates a path relative to the workspace root to be used by the application directory, e.g.
+// "build/cache". The path is resolved against the result of Root for the application name. An absolute path is used as
+// is, similar to WithPath.
+func WithRelativePath(path string) Option {
+	return relativePathOption{Path: path}
+}
+
+// WithRootConfig resolves the default path of the Config directory type to the workspace root (see Root), instead of
+// the user-specific config directory. This restores the behavior of previous versions, in which the Config and
+// Workspace directories shared the same default path. The option has no effect on other directory types.
+func WithRootConfig() Option {
+	return rootConfigOption{}
+}
+
+// WithSymlinks instructs CopyTo to copy symbolic links as links, instead of skipping them.
+func WithSymlinks() CopyOption {
+	return symlinksOption{}
+}
+
 //======================================================================================================================
 // endregion
 //======================================================================================================================
@@ -0,0 +1,52 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Test Functions
+//======================================================================================================================
+
+func TestConfigDarwin(t *testing.T) {
+	home := t.TempDir()
+	setEnv(t, "HOME", home)
+
+	// test the config directory is derived from the Library folder
+	d, e := NewDir(Config, appName)
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(home, "Library", "Application Support", appName), d.Path())
+
+	// test the workspace directory is still derived from the workspace root
+	root, e := Root(appName)
+	require.Nil(t, e)
+	w, e := NewDir(Workspace, appName)
+	require.Nil(t, e)
+	assert.Equal(t, root, w.Path())
+	assert.NotEqual(t, d.Path(), w.Path())
+
+	// test an explicit path takes precedence
+	explicit := filepath.Join(t.TempDir(), "config")
+	d, e = NewDir(Config, appName, WithPath(explicit))
+	require.Nil(t, e)
+	assert.Equal(t, explicit, d.Path())
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
@@ -0,0 +1,68 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Test Functions
+//======================================================================================================================
+
+func TestLoadXDGUserDirs(t *testing.T) {
+	home := t.TempDir()
+	config := t.TempDir()
+	setEnv(t, "HOME", home)
+	setEnv(t, "XDG_CONFIG_HOME", config)
+
+	// test a missing file is ignored
+	userDirs, e := LoadXDGUserDirs()
+	require.Nil(t, e)
+	assert.Len(t, userDirs, 0)
+
+	// test a sample file
+	sample := `# This file is written by xdg-user-dirs-update
+XDG_DESKTOP_DIR="$HOME"
+XDG_DOCUMENTS_DIR="$HOME/Docs"
+XDG_MUSIC_DIR="/srv/music"
+XDG_VIDEOS_DIR="Videos"
+`
+	require.Nil(t, os.WriteFile(filepath.Join(config, "user-dirs.dirs"), []byte(sample), 0600))
+	userDirs, e = LoadXDGUserDirs()
+	require.Nil(t, e)
+	expected := map[string]string{
+		"XDG_DESKTOP_DIR":   home,
+		"XDG_DOCUMENTS_DIR": filepath.Join(home, "Docs"),
+		"XDG_MUSIC_DIR":     "/srv/music",
+	}
+	assert.Equal(t, expected, userDirs)
+
+	// test the results are applied to a custom directory type
+	documents, e := RegisterDirType("documents", func(appName string) (string, error) { return home, nil },
+		[]string{"$DOCUMENTS"})
+	require.Nil(t, e)
+	t.Cleanup(func() { unregisterDirType(documents) })
+	dirs := NewTestAppDirs(t, appName)
+	dirs.ApplyXDGUserDirs(userDirs, map[string]DirType{"XDG_DOCUMENTS_DIR": documents, "XDG_PICTURES_DIR": Cache})
+	assert.Equal(t, filepath.Join(home, "Docs", "file"), dirs.MakeAbsolute(home, filepath.Join("$DOCUMENTS", "file")))
+	assert.NotEqual(t, home, dirs.Cache())
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
@@ -8,11 +8,15 @@ package workspace
 //======================================================================================================================
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -39,7 +43,7 @@ func TestNewDir(t *testing.T) {
 
 	_, e = NewDir(Cache, appName, WithPath("test"))
 	assert.EqualError(t, e, "cannot process relative path: test")
-
+	assert.True(t, errors.Is(e, ErrRelativePath))
 }
 
 func TestAliases(t *testing.T) {
@@ -66,6 +70,7 @@ func TestString(t *testing.T) {
 		{Type: Home, Expected: "home"},
 		{Type: Workspace, Expected: "workspace"},
 		{Type: Temp, Expected: "temp"},
+		{Type: PersistentTemp, Expected: "persistent-temp"},
 		{Type: 0, Expected: ""},
 	}
 
@@ -88,6 +93,9 @@ func TestAbsPath(t *testing.T) {
 	if runtime.GOOS != "windows" {
 		tests = []test{
 			{BasePath: "", Path: "~", Expected: home},
+			{BasePath: "", Path: filepath.Join("~", "x"), Expected: filepath.Join(home, "x")},
+			{BasePath: home, Path: filepath.Join("a", "~", "b"), Expected: filepath.Join(home, "a", "~", "b")},
+			{BasePath: home, Path: "~backup", Expected: filepath.Join(home, "~backup")},
 			{BasePath: home, Path: "test", Expected: filepath.Join(home, "test")},
 			{BasePath: home, Path: fmt.Sprintf("%c%s", filepath.Separator, "test"), Expected: fmt.Sprintf("%c%s", filepath.Separator, "test")},
 		}
@@ -125,6 +133,633 @@ func TestRoot(t *testing.T) {
 	}
 }
 
+func TestRootFromArgs(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "cmd", "app")
+	require.Nil(t, os.MkdirAll(sub, 0755))
+	require.Nil(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+
+	// test the working directory is returned for a compiled binary
+	got, e := RootFromArgs("app", filepath.Join(root, "bin", "app"), sub)
+	require.Nil(t, e)
+	assert.Equal(t, sub, got)
+
+	// test the repository root is returned when running from source
+	got, e = RootFromArgs("app", filepath.Join(os.TempDir(), "go-build", "app.test"), sub)
+	require.Nil(t, e)
+	assert.Equal(t, root, got)
+
+	// test a working directory without repository
+	_, e = RootFromArgs("app", "app.test", t.TempDir())
+	assert.EqualError(t, e, "cannot identify workspace root (no .git repository found)")
+}
+
+func TestCopyTo(t *testing.T) {
+	src := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(src, "sub", "nested"), 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(src, "a.txt"), []byte("a"), 0600))
+	require.Nil(t, os.WriteFile(filepath.Join(src, "sub", "b.txt"), []byte("b"), 0644))
+	hasLinks := runtime.GOOS != "windows"
+	if hasLinks {
+		require.Nil(t, os.Symlink("a.txt", filepath.Join(src, "link")))
+	}
+
+	d, e := NewDir(Cache, appName, WithPath(src))
+	require.Nil(t, e)
+
+	// test the files and modes are copied, skipping symbolic links
+	dest := filepath.Join(t.TempDir(), "backup")
+	require.Nil(t, d.CopyTo(dest))
+	content, e := os.ReadFile(filepath.Join(dest, "sub", "b.txt"))
+	require.Nil(t, e)
+	assert.Equal(t, "b", string(content))
+	assert.DirExists(t, filepath.Join(dest, "sub", "nested"))
+	if runtime.GOOS != "windows" {
+		info, e := os.Stat(filepath.Join(dest, "a.txt"))
+		require.Nil(t, e)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+	if hasLinks {
+		_, e = os.Lstat(filepath.Join(dest, "link"))
+		assert.True(t, os.IsNotExist(e))
+
+		// test symbolic links are copied as links
+		dest = filepath.Join(t.TempDir(), "links")
+		require.Nil(t, d.CopyTo(dest, WithSymlinks()))
+		link, e := os.Readlink(filepath.Join(dest, "link"))
+		require.Nil(t, e)
+		assert.Equal(t, "a.txt", link)
+	}
+
+	// test copying a directory into itself
+	e = d.CopyTo(filepath.Join(src, "sub"))
+	assert.EqualError(t, e, fmt.Sprintf("cannot copy directory into itself: %s", filepath.Join(src, "sub")))
+}
+
+func TestNewDirAppName(t *testing.T) {
+	base, e := os.UserCacheDir()
+	require.Nil(t, e)
+
+	// test a clean name
+	d, e := NewDir(Cache, "my-app")
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(base, "my-app"), d.Path())
+
+	// test names with a separator or a reference to a parent directory
+	for _, name := range []string{"my/app", "..", ".", filepath.Join("..", "app")} {
+		_, e = NewDir(Cache, name)
+		assert.True(t, errors.Is(e, ErrInvalidAppName), name)
+	}
+
+	// test a subdirectory-style name
+	d, e = NewDir(Cache, "vendor/app", WithNestedName())
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(base, "vendor", "app"), d.Path())
+
+	_, e = NewDir(Cache, "vendor/../app", WithNestedName())
+	assert.EqualError(t, e, "invalid application name: vendor/../app")
+
+	// test empty and whitespace-only names
+	for _, name := range []string{"", " ", "\t\n"} {
+		_, e = NewDir(Cache, name)
+		assert.EqualError(t, e, "invalid application name: name is empty")
+		_, e = NewDir(Temp, name, WithNestedName())
+		assert.True(t, errors.Is(e, ErrInvalidAppName), name)
+	}
+}
+
+func TestDefaultMode(t *testing.T) {
+	type test struct {
+		Type     DirType
+		Expected os.FileMode
+	}
+
+	tests := []test{
+		{Type: Cache, Expected: 0755},
+		{Type: Config, Expected: 0755},
+		{Type: Home, Expected: 0755},
+		{Type: Workspace, Expected: 0755},
+		{Type: Temp, Expected: 0700},
+		{Type: PersistentTemp, Expected: 0700},
+		{Type: 0, Expected: 0755},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.Expected, test.Type.DefaultMode())
+	}
+}
+
+func TestRegisterDirType(t *testing.T) {
+	root := t.TempDir()
+	data, e := RegisterDirType("data", func(appName string) (string, error) {
+		return filepath.Join(root, appName), nil
+	}, []string{"$DATA", "${DATA}"})
+	require.Nil(t, e)
+	t.Cleanup(func() { unregisterDirType(data) })
+
+	// test the custom type is allocated beyond the built-in types
+	assert.Greater(t, int(data), int(Temp))
+	assert.Equal(t, "data", data.String())
+
+	// test the custom type is supported by NewDir
+	d, e := NewDir(data, appName)
+	require.Nil(t, e)
+	assert.Equal(t, data, d.DirType())
+	assert.Equal(t, filepath.Join(root, appName), d.Path())
+	assert.Equal(t, []string{"$DATA", "${DATA}"}, d.Aliases())
+
+	// test the custom type is supported by AppDirs
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+	dirs.Assign(*d)
+	assert.Equal(t, filepath.Join(root, appName, "test"), dirs.MakeAbsolute(dirs.Workspace(), filepath.Join("$DATA", "test")))
+	assert.Equal(t, filepath.Join("$DATA", "test"), dirs.Parameterize(dirs.Workspace(), filepath.Join(root, appName, "test")))
+
+	// test the names of existing types are rejected
+	for _, name := range []string{"data", "cache", "persistent-temp"} {
+		_, e = RegisterDirType(name, func(appName string) (string, error) { return root, nil }, nil)
+		assert.True(t, errors.Is(e, ErrDirTypeExists), name)
+	}
+
+	// test the registry is restored when a type is unregistered
+	count := len(registeredDirTypes())
+	unregisterDirType(data)
+	assert.Len(t, registeredDirTypes(), count-1)
+	assert.Equal(t, "", data.String())
+	unregisterDirType(Cache)
+	assert.Len(t, registeredDirTypes(), count-1)
+}
+
+func TestPlan9Dirs(t *testing.T) {
+	home := t.TempDir()
+	setEnv(t, "home", home)
+
+	// test the documented Plan 9 conventions
+	cache, e := cacheDir("plan9")
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(home, "lib", "cache"), cache)
+	assert.Equal(t, "/tmp", tempDir("plan9"))
+
+	// test an undefined home directory
+	require.Nil(t, os.Unsetenv("home"))
+	_, e = cacheDir("plan9")
+	assert.EqualError(t, e, "$home is not defined")
+
+	// test other operating systems use the default directories
+	expected, e := os.UserCacheDir()
+	require.Nil(t, e)
+	cache, e = cacheDir("linux")
+	require.Nil(t, e)
+	assert.Equal(t, expected, cache)
+	assert.Equal(t, os.TempDir(), tempDir("linux"))
+}
+
+func TestRootFromArgsWorktree(t *testing.T) {
+	root := t.TempDir()
+	worktree := filepath.Join(root, "worktree")
+	sub := filepath.Join(worktree, "cmd")
+	require.Nil(t, os.MkdirAll(sub, 0755))
+	require.Nil(t, os.Mkdir(filepath.Join(root, ".git"), 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: ../.git/worktrees/worktree\n"), 0644))
+
+	// test the worktree root is returned instead of the main repository
+	got, e := RootFromArgs("app", "app.test", sub)
+	require.Nil(t, e)
+	assert.Equal(t, worktree, got)
+}
+
+func TestAvailableBytes(t *testing.T) {
+	switch runtime.GOOS {
+	case "darwin", "dragonfly", "freebsd", "linux", "windows":
+	default:
+		t.Skip("available space is not supported on " + runtime.GOOS)
+	}
+
+	// test an existing directory
+	d, e := NewDir(Temp, appName, WithPath(t.TempDir()))
+	require.Nil(t, e)
+	available, e := d.AvailableBytes()
+	require.Nil(t, e)
+	assert.Greater(t, available, uint64(0))
+
+	// test a directory that does not exist yet
+	d, e = NewDir(Temp, appName, WithPath(filepath.Join(t.TempDir(), "missing", "dir")))
+	require.Nil(t, e)
+	available, e = d.AvailableBytes()
+	require.Nil(t, e)
+	assert.Greater(t, available, uint64(0))
+}
+
+func TestIsDefault(t *testing.T) {
+	// test a default cache directory
+	d, e := NewDir(Cache, appName)
+	require.Nil(t, e)
+	isDefault, e := d.IsDefault(appName)
+	require.Nil(t, e)
+	assert.True(t, isDefault)
+
+	// test an overridden cache directory
+	d, e = NewDir(Cache, appName, WithPath(t.TempDir()))
+	require.Nil(t, e)
+	isDefault, e = d.IsDefault(appName)
+	require.Nil(t, e)
+	assert.False(t, isDefault)
+
+	// test an invalid application name
+	_, e = d.IsDefault("..")
+	assert.NotNil(t, e)
+}
+
+func TestNormalizeAliases(t *testing.T) {
+	d, e := NewDir(Cache, appName, WithAliases([]string{"$CACHE", "${CACHE}", "%CACHE%", "~cache", "$CACHE_DIR"}))
+	require.Nil(t, e)
+
+	// test a mixed set of aliases is converted to the braced form
+	d.NormalizeAliases(Braced)
+	assert.Equal(t, []string{"${CACHE}", "~cache", "${CACHE_DIR}"}, d.Aliases())
+
+	// test the aliases are converted back to the bare form
+	d.NormalizeAliases(Bare)
+	assert.Equal(t, []string{"$CACHE", "~cache", "$CACHE_DIR"}, d.Aliases())
+}
+
+func TestSnapshot(t *testing.T) {
+	d, e := NewDir(Temp, appName, WithPath(t.TempDir()))
+	require.Nil(t, e)
+	require.Nil(t, os.Mkdir(filepath.Join(d.Path(), "sub"), 0700))
+	require.Nil(t, os.WriteFile(filepath.Join(d.Path(), "keep"), []byte("keep"), 0600))
+	require.Nil(t, os.WriteFile(filepath.Join(d.Path(), "modify"), []byte("modify"), 0600))
+	require.Nil(t, os.WriteFile(filepath.Join(d.Path(), "sub", "remove"), []byte("remove"), 0600))
+
+	before, e := d.Snapshot()
+	require.Nil(t, e)
+	assert.Len(t, before, 3)
+
+	// test an unchanged directory has no differences
+	after, e := d.Snapshot()
+	require.Nil(t, e)
+	added, removed, changed := DiffSnapshots(before, after)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+	assert.Empty(t, changed)
+
+	// test added, removed, and changed files are reported
+	require.Nil(t, os.WriteFile(filepath.Join(d.Path(), "sub", "add"), []byte("add"), 0600))
+	require.Nil(t, os.Remove(filepath.Join(d.Path(), "sub", "remove")))
+	modified := time.Now().Add(time.Hour)
+	require.Nil(t, os.Chtimes(filepath.Join(d.Path(), "modify"), modified, modified))
+
+	after, e = d.Snapshot()
+	require.Nil(t, e)
+	added, removed, changed = DiffSnapshots(before, after)
+	assert.Equal(t, []string{filepath.Join("sub", "add")}, added)
+	assert.Equal(t, []string{filepath.Join("sub", "remove")}, removed)
+	assert.Equal(t, []string{"modify"}, changed)
+
+	// test a directory that does not exist
+	d, e = NewDir(Temp, appName, WithPath(filepath.Join(t.TempDir(), "missing")))
+	require.Nil(t, e)
+	_, e = d.Snapshot()
+	assert.NotNil(t, e)
+}
+
+func TestPruneEmpty(t *testing.T) {
+	d, e := NewDir(Cache, appName, WithPath(t.TempDir()))
+	require.Nil(t, e)
+	require.Nil(t, os.MkdirAll(filepath.Join(d.Path(), "a", "b", "c"), 0755))
+	require.Nil(t, os.MkdirAll(filepath.Join(d.Path(), "a", "d"), 0755))
+	require.Nil(t, os.MkdirAll(filepath.Join(d.Path(), "keep", "empty"), 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(d.Path(), "keep", "file"), []byte("keep"), 0600))
+
+	// test the nested empty structure is pruned
+	removed, e := d.PruneEmpty()
+	require.Nil(t, e)
+	assert.Equal(t, 5, removed)
+	assert.NoDirExists(t, filepath.Join(d.Path(), "a"))
+	assert.NoDirExists(t, filepath.Join(d.Path(), "keep", "empty"))
+	assert.FileExists(t, filepath.Join(d.Path(), "keep", "file"))
+
+	// test the root remains when empty
+	require.Nil(t, os.RemoveAll(filepath.Join(d.Path(), "keep")))
+	removed, e = d.PruneEmpty()
+	require.Nil(t, e)
+	assert.Equal(t, 0, removed)
+	assert.DirExists(t, d.Path())
+}
+
+func TestDefaultPaths(t *testing.T) {
+	// test all types are resolved
+	paths, e := DefaultPaths(appName)
+	require.Nil(t, e)
+	for _, dirType := range []DirType{Cache, Config, Home, Workspace, Temp} {
+		assert.NotEmpty(t, paths[dirType], dirType.String())
+	}
+
+	// test the errors are aggregated
+	paths, e = DefaultPaths("..")
+	assert.Len(t, paths, 0)
+	assert.True(t, strings.HasPrefix(e.Error(), "cannot resolve default paths: cache (invalid application name: ..), "))
+}
+
+func TestExpandHomeFor(t *testing.T) {
+	type test struct {
+		GOOS     string
+		Home     string
+		Path     string
+		Expected string
+	}
+
+	tests := []test{
+		{GOOS: "linux", Home: "/home/user", Path: "~", Expected: "/home/user"},
+		{GOOS: "linux", Home: "/home/user", Path: "~/x", Expected: "/home/user/x"},
+		{GOOS: "linux", Home: "/home/user", Path: "a/~/b", Expected: "a/~/b"},
+		{GOOS: "linux", Home: "/home/user", Path: "~backup", Expected: "~backup"},
+		{GOOS: "windows", Home: `C:\Users\user`, Path: "~", Expected: "~"},
+		{GOOS: "windows", Home: `C:\Users\user`, Path: `~\x`, Expected: `~\x`},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.Expected, ExpandHomeFor(test.GOOS, test.Home, test.Path), test.GOOS+": "+test.Path)
+	}
+}
+
+func TestDefaultAliases(t *testing.T) {
+	// test the temp aliases include all eight forms
+	aliases := DefaultAliases(Temp)
+	for _, alias := range []string{"$TEMP", "${TEMP}", "$TMP", "${TMP}", "$TMPDIR", "${TMPDIR}", "$TEMPDIR", "${TEMPDIR}"} {
+		assert.Contains(t, aliases, alias)
+	}
+
+	// test a copy is returned
+	aliases[0] = "$CHANGED"
+	assert.NotContains(t, DefaultAliases(Temp), "$CHANGED")
+
+	// test other types
+	assert.Equal(t, []string{"$CACHE", "${CACHE}"}, DefaultAliases(Cache))
+	assert.Len(t, DefaultAliases(Config), 0)
+	assert.Len(t, DefaultAliases(0), 0)
+}
+
+func TestCanonicalAlias(t *testing.T) {
+	// test the first default alias is canonical for temp, regardless of the order of the aliases
+	d, e := NewDir(Temp, appName)
+	require.Nil(t, e)
+	expected := DefaultAliases(Temp)[0]
+	assert.Equal(t, expected, d.CanonicalAlias())
+	d.AppendAliases("$T")
+	assert.Equal(t, expected, d.CanonicalAlias())
+	d.RemoveAliases(expected)
+	assert.Equal(t, DefaultAliases(Temp)[1], d.CanonicalAlias())
+	d.RemoveAliases(DefaultAliases(Temp)...)
+	assert.Equal(t, "$T", d.CanonicalAlias())
+
+	// test the shortest bare alias is canonical for custom aliases, resolving ties alphabetically
+	d, e = NewDir(Cache, appName, WithAliases([]string{"${C}", "$LONG", "$BB", "$AA"}))
+	require.Nil(t, e)
+	assert.Equal(t, "$AA", d.CanonicalAlias())
+	d.AppendAliases("%Z%")
+	assert.Equal(t, "$AA", d.CanonicalAlias())
+
+	// test the shortest alias is canonical when no bare alias is available
+	d, e = NewDir(Cache, appName, WithAliases([]string{"${LONG}", "%C%"}))
+	require.Nil(t, e)
+	assert.Equal(t, "%C%", d.CanonicalAlias())
+
+	// test a directory without aliases
+	d.RemoveAliases("${LONG}", "%C%")
+	assert.Equal(t, "", d.CanonicalAlias())
+
+	// test the canonical alias is used for parameterization
+	dirs := &AppDirs{}
+	d, e = NewDir(Cache, appName, WithPath(t.TempDir()), WithAliases([]string{"$ZCACHE", "$CACHE_DIR"}))
+	require.Nil(t, e)
+	d.AppendAliases("$ACACHE_LONG")
+	dirs.Assign(*d)
+	assert.Equal(t, filepath.Join("$ZCACHE", "file"), dirs.ParameterizeRaw("", filepath.Join(d.Path(), "file")))
+}
+
+func TestRootCache(t *testing.T) {
+	// count the filesystem access while locating the workspace root
+	var count int
+	stat := statFile
+	statFile = func(name string) (os.FileInfo, error) {
+		count++
+		return stat(name)
+	}
+	t.Cleanup(func() {
+		statFile = stat
+		ResetRootCache()
+	})
+	ResetRootCache()
+
+	// test the second call is served from the cache
+	first, e := Root("go-workspace")
+	require.Nil(t, e)
+	accessed := count
+	assert.Greater(t, accessed, 0)
+	second, e := Root("go-workspace")
+	require.Nil(t, e)
+	assert.Equal(t, first, second)
+	assert.Equal(t, accessed, count)
+
+	// test the cache is invalidated
+	ResetRootCache()
+	_, e = Root("go-workspace")
+	require.Nil(t, e)
+	assert.Greater(t, count, accessed)
+}
+
+func TestValidatePathFor(t *testing.T) {
+	type test struct {
+		GOOS  string
+		Path  string
+		Valid bool
+	}
+
+	long := strings.Repeat("a", 256)
+	tests := []test{
+		{GOOS: "linux", Path: "/home/user/con/aux.txt", Valid: true},
+		{GOOS: "windows", Path: "/home/user/con/aux.txt", Valid: false},
+		{GOOS: "linux", Path: "data/report?.txt", Valid: true},
+		{GOOS: "windows", Path: "data/report?.txt", Valid: false},
+		{GOOS: "linux", Path: "data/name.", Valid: true},
+		{GOOS: "windows", Path: "data/name.", Valid: false},
+		{GOOS: "windows", Path: `C:\Users\user\AppData\Local\app`, Valid: true},
+		{GOOS: "windows", Path: `C:\Users\user\a:b`, Valid: false},
+		{GOOS: "windows", Path: `..\shared\PRN.log`, Valid: false},
+		{GOOS: "windows", Path: `shared\console.log`, Valid: true},
+		{GOOS: "windows", Path: `\\?\C:\` + strings.Repeat(`dir\`, 100), Valid: true},
+		{GOOS: "windows", Path: `C:\` + strings.Repeat(`dir\`, 100), Valid: false},
+		{GOOS: "linux", Path: "/tmp/" + long, Valid: false},
+		{GOOS: "darwin", Path: "/" + strings.Repeat("dir/", 300), Valid: false},
+		{GOOS: "linux", Path: "/" + strings.Repeat("dir/", 300), Valid: true},
+		{GOOS: "linux", Path: "/tmp/a\x00b", Valid: false},
+		{GOOS: "linux", Path: "", Valid: false},
+	}
+
+	for _, test := range tests {
+		e := ValidatePathFor(test.GOOS, test.Path)
+		if test.Valid {
+			assert.Nil(t, e, test.GOOS+": "+test.Path)
+		} else {
+			assert.True(t, errors.Is(e, ErrInvalidPath), test.GOOS+": "+test.Path)
+		}
+	}
+}
+
+func TestHash(t *testing.T) {
+	d, e := NewDir(Cache, appName, WithPath(t.TempDir()))
+	require.Nil(t, e)
+	require.Nil(t, os.Mkdir(filepath.Join(d.Path(), "sub"), 0700))
+	require.Nil(t, os.WriteFile(filepath.Join(d.Path(), "sub", "file"), []byte("content"), 0600))
+
+	// test the hash is stable for unchanged contents, regardless of modification times
+	first, e := d.Hash()
+	require.Nil(t, e)
+	assert.Len(t, first, 64)
+	modified := time.Now().Add(time.Hour)
+	require.Nil(t, os.Chtimes(filepath.Join(d.Path(), "sub", "file"), modified, modified))
+	second, e := d.Hash()
+	require.Nil(t, e)
+	assert.Equal(t, first, second)
+
+	// test the hash changes when the contents of a file change
+	require.Nil(t, os.WriteFile(filepath.Join(d.Path(), "sub", "file"), []byte("changed"), 0600))
+	changed, e := d.Hash()
+	require.Nil(t, e)
+	assert.NotEqual(t, first, changed)
+
+	// test the target of a symbolic link is not hashed
+	if runtime.GOOS != "windows" {
+		target := filepath.Join(t.TempDir(), "target")
+		require.Nil(t, os.WriteFile(target, []byte("target"), 0600))
+		require.Nil(t, os.Symlink(target, filepath.Join(d.Path(), "link")))
+		linked, e := d.Hash()
+		require.Nil(t, e)
+		assert.NotEqual(t, changed, linked)
+		require.Nil(t, os.WriteFile(target, []byte("modified target"), 0600))
+		unchanged, e := d.Hash()
+		require.Nil(t, e)
+		assert.Equal(t, linked, unchanged)
+	}
+
+	// test a missing directory
+	missing, e := NewDir(Cache, appName, WithPath(filepath.Join(d.Path(), "missing")))
+	require.Nil(t, e)
+	_, e = missing.Hash()
+	assert.NotNil(t, e)
+}
+
+func TestWithRelativePath(t *testing.T) {
+	root, e := Root(appName)
+	require.Nil(t, e)
+
+	// test a relative path is resolved against the workspace root
+	d, e := NewDir(Cache, appName, WithRelativePath(filepath.Join("build", "cache")))
+	require.Nil(t, e)
+	assert.True(t, filepath.IsAbs(d.Path()))
+	assert.Equal(t, filepath.Join(root, "build", "cache"), d.Path())
+
+	// test an absolute path is used as is
+	abs := t.TempDir()
+	d, e = NewDir(Cache, appName, WithRelativePath(abs))
+	require.Nil(t, e)
+	assert.Equal(t, abs, d.Path())
+
+	// test WithPath still rejects a relative path
+	_, e = NewDir(Cache, appName, WithPath("relative"))
+	assert.True(t, errors.Is(e, ErrRelativePath))
+
+	// test the cause is wrapped when the workspace root cannot be identified
+	wd, e := os.Getwd()
+	require.Nil(t, e)
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	require.Nil(t, os.Chdir(t.TempDir()))
+	_, e = NewDir(Cache, appName, WithRelativePath("relative"))
+	require.NotNil(t, e)
+	assert.NotNil(t, errors.Unwrap(e))
+	assert.EqualError(t, e, "cannot initialize directory: cache: cannot identify workspace root (no .git repository found)")
+}
+
+func TestAppendAliasesStrict(t *testing.T) {
+	type test struct {
+		Alias string
+		Valid bool
+	}
+
+	tests := []test{
+		{Alias: "$DATA", Valid: true},
+		{Alias: "${DATA}", Valid: true},
+		{Alias: "%DATA%", Valid: true},
+		{Alias: "~", Valid: true},
+		{Alias: "$_data_2", Valid: true},
+		{Alias: "$workspaceRoot:docs", Valid: true},
+		{Alias: "${workspaceRoot:docs}", Valid: true},
+		{Alias: "DATA", Valid: false},
+		{Alias: "$", Valid: false},
+		{Alias: "${}", Valid: false},
+		{Alias: "$2DATA", Valid: false},
+		{Alias: "$DA-TA", Valid: false},
+		{Alias: "${DATA", Valid: false},
+		{Alias: "%DATA", Valid: false},
+		{Alias: "%DA:TA%", Valid: false},
+		{Alias: "$DATA:", Valid: false},
+		{Alias: "$DATA:a/b", Valid: false},
+		{Alias: "~user", Valid: false},
+	}
+
+	for _, test := range tests {
+		d, e := NewDir(Cache, appName, WithAliases([]string{}))
+		require.Nil(t, e)
+		e = d.AppendAliasesStrict(test.Alias)
+		if test.Valid {
+			assert.Nil(t, e, test.Alias)
+			assert.Contains(t, d.Aliases(), test.Alias)
+		} else {
+			assert.True(t, errors.Is(e, ErrInvalidAlias), test.Alias)
+		}
+	}
+
+	// test no aliases are appended if any of them is invalid
+	d, e := NewDir(Cache, appName, WithAliases([]string{"$A"}))
+	require.Nil(t, e)
+	assert.NotNil(t, d.AppendAliasesStrict("$B", "C"))
+	assert.Equal(t, []string{"$A"}, d.Aliases())
+}
+
+func TestDirSchema(t *testing.T) {
+	var entries []struct {
+		Name    string   `json:"name"`
+		Pattern string   `json:"pattern"`
+		Aliases []string `json:"aliases"`
+	}
+	require.Nil(t, json.Unmarshal(DirSchema(), &entries))
+
+	// test the schema lists all registered types, including their default aliases
+	types := registeredDirTypes()
+	require.Equal(t, len(types), len(entries))
+	for i, d := range types {
+		assert.Equal(t, d.String(), entries[i].Name)
+		assert.Equal(t, DefaultAliases(d), entries[i].Aliases)
+		assert.Equal(t, defaultPattern(runtime.GOOS, d), entries[i].Pattern)
+	}
+	for _, d := range []DirType{Cache, Config, Home, Workspace, Temp, PersistentTemp} {
+		assert.NotEmpty(t, defaultPattern(runtime.GOOS, d), d.String())
+	}
+
+	// test the patterns of the supported operating systems
+	assert.Equal(t, "${XDG_CACHE_HOME:-$HOME/.cache}/$APP_NAME", defaultPattern("linux", Cache))
+	assert.Equal(t, "${XDG_CONFIG_HOME:-$HOME/.config}/$APP_NAME", defaultPattern("linux", Config))
+	assert.Equal(t, "${TMPDIR:-/tmp}/$APP_NAME", defaultPattern("linux", Temp))
+	assert.Equal(t, "$HOME/Library/Application Support/$APP_NAME", defaultPattern("darwin", Config))
+	assert.Equal(t, "$home/lib/cache/$APP_NAME", defaultPattern("plan9", Cache))
+	assert.Equal(t, "/tmp/$APP_NAME-persistent", defaultPattern("plan9", PersistentTemp))
+	assert.Equal(t, `%LocalAppData%\$APP_NAME`, defaultPattern("windows", Cache))
+	assert.Equal(t, `%TMP%\$APP_NAME`, defaultPattern("windows", Temp))
+	assert.Equal(t, `%TMP%\$APP_NAME-persistent`, defaultPattern("windows", PersistentTemp))
+	assert.Equal(t, "", defaultPattern("linux", DirType(0)))
+}
+
 //======================================================================================================================
 // endregion
 //======================================================================================================================
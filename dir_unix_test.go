@@ -0,0 +1,186 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Test Functions
+//======================================================================================================================
+
+func TestCacheSource(t *testing.T) {
+	if runtime.GOOS == "darwin" {
+		t.Skip("$XDG_CACHE_HOME is not supported on macOS")
+	}
+
+	// test the cache directory is derived from $XDG_CACHE_HOME
+	xdg := filepath.Join(t.TempDir(), "cache")
+	setEnv(t, "XDG_CACHE_HOME", xdg)
+	path, fromXDG, e := CacheSource()
+	require.Nil(t, e)
+	assert.Equal(t, xdg, path)
+	assert.True(t, fromXDG)
+
+	// test the cache directory is derived from the home directory
+	require.Nil(t, os.Unsetenv("XDG_CACHE_HOME"))
+	home, e := os.UserHomeDir()
+	require.Nil(t, e)
+	path, fromXDG, e = CacheSource()
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(home, ".cache"), path)
+	assert.False(t, fromXDG)
+}
+
+func TestPersistentTemp(t *testing.T) {
+	temp, e := NewDir(Temp, appName)
+	require.Nil(t, e)
+	persistent, e := NewDir(PersistentTemp, appName)
+	require.Nil(t, e)
+
+	// test the persistent path differs from the ephemeral one
+	assert.NotEqual(t, temp.Path(), persistent.Path())
+	if info, e := os.Stat("/var/tmp"); e == nil && info.IsDir() {
+		assert.Equal(t, filepath.Join("/var/tmp", appName), persistent.Path())
+	}
+
+	// test the aliases are recognized
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+	assert.Equal(t, persistent.Path(), dirs.PersistentTemp())
+	assert.Equal(t, filepath.Join(persistent.Path(), "x"), dirs.MakeAbsolute(dirs.Workspace(), "${PERSISTENT_TEMP}/x"))
+	assert.Equal(t, filepath.Join("$PERSISTENT_TEMP", "x"),
+		dirs.Parameterize(dirs.Workspace(), filepath.Join(persistent.Path(), "x")))
+
+	// test the fallback when '/var/tmp' is not available
+	original := varTmpDir
+	varTmpDir = filepath.Join(t.TempDir(), "missing")
+	t.Cleanup(func() { varTmpDir = original })
+	assert.Equal(t, filepath.Join(os.TempDir(), appName+"-persistent"), persistentTempPath(runtime.GOOS, appName))
+}
+
+func TestWalkTreeCycle(t *testing.T) {
+	root := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(root, "a", "b"), 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(root, "a", "file"), []byte("file"), 0600))
+	require.Nil(t, os.Symlink(root, filepath.Join(root, "a", "b", "loop")))
+	require.Nil(t, os.Symlink(filepath.Join(root, "a"), filepath.Join(root, "link")))
+
+	// test the walk completes and visits each directory once
+	var visited []string
+	e := walkTree(root, true, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		visited = append(visited, rel)
+		return err
+	})
+	require.Nil(t, e)
+	assert.Equal(t, []string{".", "a", filepath.Join("a", "b"), filepath.Join("a", "file")}, visited)
+
+	// test the walkers complete
+	d, e := NewDir(Temp, appName, WithPath(root))
+	require.Nil(t, e)
+	snapshot, e := d.Snapshot()
+	require.Nil(t, e)
+	assert.Len(t, snapshot, 3)
+	assert.Contains(t, snapshot, "link")
+	removed, e := d.PruneEmpty()
+	require.Nil(t, e)
+	assert.Equal(t, 0, removed)
+}
+
+func TestChmod(t *testing.T) {
+	root := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(root, "dir", "sub"), 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(root, "dir", "sub", "file"), []byte("file"), 0644))
+
+	// link to a file outside of the directory
+	outside := filepath.Join(t.TempDir(), "outside")
+	require.Nil(t, os.WriteFile(outside, []byte("outside"), 0644))
+	require.Nil(t, os.Symlink(outside, filepath.Join(root, "dir", "link")))
+
+	d, e := NewDir(Cache, appName, WithPath(filepath.Join(root, "dir")))
+	require.Nil(t, e)
+
+	// test only the directory itself is updated when not recursive
+	require.Nil(t, d.Chmod(0750, false))
+	expected := map[string]os.FileMode{
+		d.Path():                               0750,
+		filepath.Join(d.Path(), "sub"):         0755,
+		filepath.Join(d.Path(), "sub", "file"): 0644,
+		outside:                                0644,
+	}
+	for path, mode := range expected {
+		info, e := os.Stat(path)
+		require.Nil(t, e)
+		assert.Equal(t, mode, info.Mode().Perm(), path)
+	}
+
+	// test the mode is applied recursively, without following the link
+	require.Nil(t, d.Chmod(0700, true))
+	expected = map[string]os.FileMode{
+		d.Path():                               0700,
+		filepath.Join(d.Path(), "sub"):         0700,
+		filepath.Join(d.Path(), "sub", "file"): 0700,
+		outside:                                0644,
+	}
+	for path, mode := range expected {
+		info, e := os.Stat(path)
+		require.Nil(t, e)
+		assert.Equal(t, mode, info.Mode().Perm(), path)
+	}
+
+	// test a missing directory
+	missing, e := NewDir(Cache, appName, WithPath(filepath.Join(root, "missing")))
+	require.Nil(t, e)
+	assert.NotNil(t, missing.Chmod(0700, true))
+}
+
+func TestBestTempDir(t *testing.T) {
+	// test the temp directory is derived from $TMPDIR
+	tmp := t.TempDir()
+	setEnv(t, "TMPDIR", tmp)
+	path, source, e := BestTempDir()
+	require.Nil(t, e)
+	assert.Equal(t, tmp, path)
+	assert.Equal(t, "TMPDIR", source)
+
+	// test a missing directory is reported
+	missing := filepath.Join(tmp, "missing")
+	setEnv(t, "TMPDIR", missing)
+	path, source, e = BestTempDir()
+	assert.NotNil(t, e)
+	assert.Equal(t, missing, path)
+	assert.Equal(t, "TMPDIR", source)
+
+	// test the system default
+	require.Nil(t, os.Unsetenv("TMPDIR"))
+	path, source, _ = BestTempDir()
+	assert.Equal(t, os.TempDir(), path)
+	assert.Equal(t, path, source)
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
@@ -0,0 +1,32 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!windows
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"errors"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Functions
+//======================================================================================================================
+
+// availableBytes is not supported on the current platform and always returns an error.
+func availableBytes(path string) (uint64, error) {
+	return 0, errors.New("cannot determine available space, unsupported platform")
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
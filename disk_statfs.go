@@ -0,0 +1,36 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+//go:build darwin || dragonfly || freebsd || linux
+// +build darwin dragonfly freebsd linux
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"syscall"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Functions
+//======================================================================================================================
+
+// availableBytes returns the free space available to the current user on the filesystem containing path.
+func availableBytes(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if e := syscall.Statfs(path, &stat); e != nil {
+		return 0, e
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
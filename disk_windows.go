@@ -0,0 +1,55 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Variables
+//======================================================================================================================
+
+var procGetDiskFreeSpaceEx = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Functions
+//======================================================================================================================
+
+// availableBytes returns the free space available to the current user on the volume containing path.
+func availableBytes(path string) (uint64, error) {
+	p, e := syscall.UTF16PtrFromString(path)
+	if e != nil {
+		return 0, e
+	}
+
+	var available, total, free uint64
+	r, _, e := procGetDiskFreeSpaceEx.Call(
+		uintptr(unsafe.Pointer(p)),
+		uintptr(unsafe.Pointer(&available)),
+		uintptr(unsafe.Pointer(&total)),
+		uintptr(unsafe.Pointer(&free)),
+	)
+	if r == 0 {
+		return 0, e
+	}
+	return available, nil
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
@@ -0,0 +1,134 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"sync"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Public Types
+//======================================================================================================================
+
+// LazyAppDirs resolves the application directories on first access instead of during initialization. A directory
+// that cannot be resolved returns an empty path, the error is recorded and can be retrieved using Err. The resolved
+// paths and errors are cached. LazyAppDirs is safe for concurrent use. Only the Temp and PersistentTemp directories
+// always resolve, as they fall back to the system temp directory. No fallback is applied to the other directories, e.g.
+// Cache, Config, and Home remain empty when '$HOME' is not defined, and Workspace remains empty when its root cannot
+// be determined.
+type LazyAppDirs struct {
+	appName string
+	mutex   sync.Mutex
+	dirs    map[DirType]*Dir
+	errs    map[DirType]error
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Functions
+//======================================================================================================================
+
+// resolve returns the directory of the given type, resolving it on first access. It returns nil if the directory
+// cannot be resolved.
+func (l *LazyAppDirs) resolve(dirType DirType) *Dir {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if d, found := l.dirs[dirType]; found {
+		return d
+	}
+	if _, found := l.errs[dirType]; found {
+		return nil
+	}
+
+	d, e := NewDir(dirType, l.appName)
+	if e != nil {
+		l.errs[dirType] = e
+		return nil
+	}
+	l.dirs[dirType] = d
+	return d
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Public Functions
+//======================================================================================================================
+
+// NewLazyAppDirs initializes a LazyAppDirs type for the given application name. It never fails, the directories are
+// resolved on first access using NewDir.
+func NewLazyAppDirs(appName string) *LazyAppDirs {
+	return &LazyAppDirs{
+		appName: appName,
+		dirs:    make(map[DirType]*Dir),
+		errs:    make(map[DirType]error),
+	}
+}
+
+// Cache resolves and returns the cache directory. It returns an empty string if the directory cannot be resolved.
+func (l *LazyAppDirs) Cache() string {
+	return l.Path(Cache)
+}
+
+// Config resolves and returns the config directory. It returns an empty string if the directory cannot be resolved.
+func (l *LazyAppDirs) Config() string {
+	return l.Path(Config)
+}
+
+// Err returns the error recorded while resolving the directory of the given type. It returns nil if the directory
+// was resolved successfully or has not been accessed yet.
+func (l *LazyAppDirs) Err(dirType DirType) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.errs[dirType]
+}
+
+// Home resolves and returns the home directory. It returns an empty string if the directory cannot be resolved.
+func (l *LazyAppDirs) Home() string {
+	return l.Path(Home)
+}
+
+// Path resolves and returns the directory of the given type, including custom types registered with
+// RegisterDirType. It returns an empty string if the directory cannot be resolved.
+func (l *LazyAppDirs) Path(dirType DirType) string {
+	if d := l.resolve(dirType); d != nil {
+		return d.Path()
+	}
+	return ""
+}
+
+// PersistentTemp resolves and returns the persistent temp directory. It returns an empty string if the directory
+// cannot be resolved.
+func (l *LazyAppDirs) PersistentTemp() string {
+	return l.Path(PersistentTemp)
+}
+
+// Temp resolves and returns the temp directory. It returns an empty string if the directory cannot be resolved.
+func (l *LazyAppDirs) Temp() string {
+	return l.Path(Temp)
+}
+
+// Workspace resolves and returns the workspace directory. It returns an empty string if the directory cannot be
+// resolved.
+func (l *LazyAppDirs) Workspace() string {
+	return l.Path(Workspace)
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
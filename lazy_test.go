@@ -0,0 +1,57 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Test Functions
+//======================================================================================================================
+
+func TestLazyAppDirs(t *testing.T) {
+	original := userCacheDir
+	userCacheDir = func() (string, error) { return "", fmt.Errorf("cache directory not available") }
+	t.Cleanup(func() { userCacheDir = original })
+
+	dirs := NewLazyAppDirs(appName)
+	assert.Nil(t, dirs.Err(Cache))
+
+	// test the failing resolver records an error
+	assert.Equal(t, "", dirs.Cache())
+	assert.EqualError(t, dirs.Err(Cache), "cannot initialize directory: cache")
+
+	// test the other resolvers succeed
+	assert.Equal(t, filepath.Join(os.TempDir(), appName), dirs.Temp())
+	assert.Nil(t, dirs.Err(Temp))
+	home, e := os.UserHomeDir()
+	require.Nil(t, e)
+	assert.Equal(t, home, dirs.Home())
+	assert.NotEmpty(t, dirs.Workspace())
+	assert.Nil(t, dirs.Err(Workspace))
+
+	// test the results are cached
+	userCacheDir = func() (string, error) { return t.TempDir(), nil }
+	assert.Equal(t, "", dirs.Cache())
+	assert.NotNil(t, dirs.Err(Cache))
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
@@ -0,0 +1,86 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Variables
+//======================================================================================================================
+
+var (
+	// pathDirs holds the application directories used to expand the keywords of a PathString, guarded by pathMutex.
+	pathDirs  *AppDirs
+	pathMutex sync.RWMutex
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Public Types
+//======================================================================================================================
+
+// PathString is a path that expands its keywords when unmarshaled from JSON. Use BindPathStrings to bind the
+// application directories used for the expansion. Declare configuration fields as PathString to resolve values such as
+// "$CACHE/x" automatically during decoding.
+type PathString string
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Public Functions
+//======================================================================================================================
+
+// BindPathStrings binds the application directories used to expand the keywords of a PathString during decoding. The
+// paths are decoded as is when no application directories are bound. Pass nil to remove the current binding.
+// BindPathStrings is safe for concurrent use.
+func BindPathStrings(dirs *AppDirs) {
+	pathMutex.Lock()
+	defer pathMutex.Unlock()
+	pathDirs = dirs
+}
+
+// String returns the path as string.
+func (p PathString) String() string {
+	return string(p)
+}
+
+// UnmarshalJSON decodes a JSON string and expands its keywords using the bound application directories. The value is
+// converted to an absolute path using MakeAbsolute, with the workspace directory as base path.
+func (p *PathString) UnmarshalJSON(data []byte) error {
+	var s string
+	if e := json.Unmarshal(data, &s); e != nil {
+		return e
+	}
+
+	pathMutex.RLock()
+	dirs := pathDirs
+	pathMutex.RUnlock()
+
+	if dirs != nil && s != "" {
+		s = dirs.MakeAbsolute(dirs.Workspace(), s)
+	}
+	*p = PathString(s)
+
+	return nil
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
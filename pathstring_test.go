@@ -0,0 +1,63 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Test Functions
+//======================================================================================================================
+
+func TestPathString(t *testing.T) {
+	type config struct {
+		Cache PathString `json:"cache"`
+		Temp  PathString `json:"temp"`
+		Name  string     `json:"name"`
+	}
+
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+	input, e := json.Marshal(map[string]string{
+		"cache": filepath.Join("$CACHE", "x"),
+		"temp":  filepath.Join("${TEMP}", "y"),
+		"name":  "$CACHE",
+	})
+	require.Nil(t, e)
+
+	// test the keywords are expanded when bound
+	BindPathStrings(dirs)
+	t.Cleanup(func() { BindPathStrings(nil) })
+	var c config
+	require.Nil(t, json.Unmarshal(input, &c))
+	assert.Equal(t, filepath.Join(dirs.Cache(), "x"), c.Cache.String())
+	assert.Equal(t, filepath.Join(dirs.Temp(), "y"), c.Temp.String())
+	assert.Equal(t, "$CACHE", c.Name)
+
+	// test the paths are decoded as is when not bound
+	BindPathStrings(nil)
+	require.Nil(t, json.Unmarshal(input, &c))
+	assert.Equal(t, filepath.Join("$CACHE", "x"), c.Cache.String())
+
+	// test invalid input
+	assert.NotNil(t, json.Unmarshal([]byte(`{"cache": 1}`), &c))
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
@@ -0,0 +1,61 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris && !windows
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris,!windows
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Functions
+//======================================================================================================================
+
+// fileKey returns a key uniquely identifying the file at path, using the path with all symbolic links resolved. The
+// cleaned path is used if the links cannot be resolved.
+func fileKey(path string, info os.FileInfo) string {
+	if resolved, e := filepath.EvalSymlinks(path); e == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// isReadOnly returns true if the error is caused by a lack of permissions. A read-only file system cannot be
+// identified on the current platform.
+func isReadOnly(err error) bool {
+	return errors.Is(err, os.ErrPermission)
+}
+
+// isTransient cannot identify temporary conditions on the current platform and always returns false.
+func isTransient(err error) bool {
+	return false
+}
+
+// processAlive cannot verify the existence of a process on the current platform and always returns true, which
+// ensures no process is considered stale incorrectly.
+func processAlive(pid int) bool {
+	return true
+}
+
+// sameVolume returns true if both paths share the same volume name. The device of a path cannot be identified on the
+// current platform.
+func sameVolume(path1 string, path2 string) (bool, error) {
+	return filepath.VolumeName(path1) == filepath.VolumeName(path2), nil
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
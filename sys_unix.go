@@ -0,0 +1,82 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Functions
+//======================================================================================================================
+
+// fileKey returns a key uniquely identifying the file described by info, composed of its device and inode number. The
+// resolved path is used if the device and inode cannot be determined.
+func fileKey(path string, info os.FileInfo) string {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok {
+		return fmt.Sprintf("%d:%d", uint64(st.Dev), uint64(st.Ino))
+	}
+	if resolved, e := filepath.EvalSymlinks(path); e == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// isReadOnly returns true if the error is caused by a lack of permissions or a read-only file system.
+func isReadOnly(err error) bool {
+	return errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
+
+// isTransient returns true if the error is likely caused by a temporary condition, such as a busy device or another
+// process adding files to a directory that is being removed.
+func isTransient(err error) bool {
+	return errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.ENOTEMPTY) || errors.Is(err, syscall.EAGAIN)
+}
+
+// processAlive returns true if a process with the given process id exists. It sends signal 0 to the process, which
+// performs the error checking without actually sending a signal.
+func processAlive(pid int) bool {
+	p, e := os.FindProcess(pid)
+	if e != nil {
+		return false
+	}
+	e = p.Signal(syscall.Signal(0))
+	return e == nil || errors.Is(e, syscall.EPERM)
+}
+
+// sameVolume returns true if both paths reside on the same device. Both paths are expected to exist.
+func sameVolume(path1 string, path2 string) (bool, error) {
+	devices := make([]uint64, 0, 2)
+	for _, path := range []string{path1, path2} {
+		info, e := os.Stat(path)
+		if e != nil {
+			return false, e
+		}
+		st, ok := info.Sys().(*syscall.Stat_t)
+		if !ok {
+			return false, fmt.Errorf("cannot identify device of path: %s", path)
+		}
+		devices = append(devices, uint64(st.Dev))
+	}
+	return devices[0] == devices[1], nil
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
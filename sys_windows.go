@@ -0,0 +1,80 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Constants
+//======================================================================================================================
+
+// errSharingViolation is returned when a file is in use by another process (ERROR_SHARING_VIOLATION).
+const errSharingViolation = syscall.Errno(32)
+
+// errWriteProtect is returned when the media is write protected (ERROR_WRITE_PROTECT).
+const errWriteProtect = syscall.Errno(19)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Functions
+//======================================================================================================================
+
+// fileKey returns a key uniquely identifying the file at path, using the path with all symbolic links resolved. The
+// cleaned path is used if the links cannot be resolved.
+func fileKey(path string, info os.FileInfo) string {
+	if resolved, e := filepath.EvalSymlinks(path); e == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
+
+// isReadOnly returns true if the error is caused by a lack of permissions or write-protected media.
+func isReadOnly(err error) bool {
+	return errors.Is(err, os.ErrPermission) || errors.Is(err, errWriteProtect)
+}
+
+// isTransient returns true if the error is likely caused by a temporary condition. On Windows, files that are briefly
+// opened by another process, such as a virus scanner or the search indexer, cannot be removed and cause an access
+// denied or sharing violation error.
+func isTransient(err error) bool {
+	return errors.Is(err, syscall.ERROR_ACCESS_DENIED) || errors.Is(err, errSharingViolation) ||
+		errors.Is(err, syscall.ERROR_DIR_NOT_EMPTY)
+}
+
+// processAlive returns true if a process with the given process id exists. On Windows, os.FindProcess fails when the
+// process does not exist.
+func processAlive(pid int) bool {
+	p, e := os.FindProcess(pid)
+	if e != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
+
+// sameVolume returns true if both paths reside on the same volume, as indicated by their volume names.
+func sameVolume(path1 string, path2 string) (bool, error) {
+	return strings.EqualFold(filepath.VolumeName(path1), filepath.VolumeName(path2)), nil
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
@@ -0,0 +1,49 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Public Functions
+//======================================================================================================================
+
+// NewTestAppDirs initializes a sandboxed AppDirs type for use in tests, including the tests of downstream packages.
+// The Cache, Config, Temp, and PersistentTemp directories point to subdirectories of t.TempDir(), the other directories
+// are initialized with their default values. The sandbox is removed automatically when the test and all its subtests
+// complete. The test fails immediately if the directories cannot be initialized.
+func NewTestAppDirs(t testing.TB, appName string) *AppDirs {
+	t.Helper()
+
+	dirs, e := NewAppDirs(appName)
+	if e != nil {
+		t.Fatalf("cannot initialize application directories: %s", e.Error())
+	}
+
+	root := t.TempDir()
+	for _, dirType := range []DirType{Cache, Config, Temp, PersistentTemp} {
+		d, e := NewDir(dirType, appName, WithPath(filepath.Join(root, dirType.String())))
+		if e != nil {
+			t.Fatalf("cannot initialize %s directory: %s", dirType.String(), e.Error())
+		}
+		dirs.Assign(*d)
+	}
+
+	return dirs
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
@@ -0,0 +1,51 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Test Functions
+//======================================================================================================================
+
+func TestNewTestAppDirs(t *testing.T) {
+	var root string
+	t.Run("sandbox", func(t *testing.T) {
+		dirs := NewTestAppDirs(t, appName)
+
+		// test the cache and temp directories share a sandboxed root within the system's temp directory
+		root = filepath.Dir(dirs.Cache())
+		assert.Equal(t, filepath.Join(root, "cache"), dirs.Cache())
+		assert.Equal(t, filepath.Join(root, "temp"), dirs.Temp())
+		_, ok := relWithin(os.TempDir(), root)
+		assert.True(t, ok)
+		assert.NotEqual(t, filepath.Clean(os.TempDir()), root)
+
+		// test the temp directory is usable
+		require.Nil(t, dirs.CreateTemp())
+		require.Nil(t, dirs.RecreateTemp("sub"))
+	})
+
+	// test the sandbox is removed once the test completes
+	assert.NoDirExists(t, root)
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
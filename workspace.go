@@ -8,18 +8,138 @@ package workspace
 //======================================================================================================================
 
 import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"hash/fnv"
+	"io"
 	"os"
+	"os/user"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"runtime"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 //======================================================================================================================
 // endregion
 //======================================================================================================================
 
+//======================================================================================================================
+// region Private Constants
+//======================================================================================================================
+
+const (
+	// maxReserveAttempts defines the maximum number of attempts to reserve a uniquely named temp subdirectory.
+	maxReserveAttempts = 100
+
+	// maxScratchDirs defines the maximum number of idle scratch directories retained for reuse.
+	maxScratchDirs = 4
+
+	// scratchPattern defines the name pattern of scratch directories within the application's temp directory.
+	scratchPattern = "scratch-*"
+
+	// tempLockFile defines the name of the lock file within the application's temp directory.
+	tempLockFile = ".lock"
+
+	// tempLockStripes defines the number of mutexes shared by the temp operations of all subdirectories.
+	tempLockStripes = 64
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Variables
+//======================================================================================================================
+
+// AppDirs implements the Resolver interface.
+var _ Resolver = (*AppDirs)(nil)
+
+var (
+	// tempFallbacks defines the writable mounts considered when the system's temp directory is read-only. Unlike the
+	// fallbacks provided by WithTempFallback, a temp directory within these mounts is trusted by RemoveTemp.
+	tempFallbacks = []string{"/dev/shm"}
+
+	// mkdir creates a single directory, it can be replaced to simulate read-only file systems.
+	mkdir = os.Mkdir
+
+	// removeAll removes a directory and its contents, it can be replaced to simulate transient failures.
+	removeAll = os.RemoveAll
+
+	// keywordPattern matches the keyword forms "${NAME}", "$NAME", and "%NAME%" within a text. The bare form may
+	// include a qualifier, such as "$workspaceRoot:name".
+	keywordPattern = regexp.MustCompile(`\$\{[^${}]+\}` +
+		`|\$[A-Za-z_][A-Za-z0-9_]*(:[A-Za-z0-9_.-]+)?` +
+		`|%[A-Za-z_][A-Za-z0-9_]*%`)
+
+	// tempLocks serializes the temp operations on the same subdirectory, striped by the hash of its path.
+	tempLocks [tempLockStripes]sync.Mutex
+
+	// tempTreeLocks serializes the temp operations on an entire temp directory with the operations on its
+	// subdirectories, striped by the hash of the temp directory's path.
+	tempTreeLocks [tempLockStripes]sync.RWMutex
+)
+
+// configFormats defines the supported configuration file extensions and their format, in order of detection.
+var configFormats = []struct {
+	ext    string
+	format string
+}{
+	{ext: ".yaml", format: "yaml"},
+	{ext: ".yml", format: "yaml"},
+	{ext: ".json", format: "json"},
+	{ext: ".toml", format: "toml"},
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Types
+//======================================================================================================================
+
+// createOptions defines the optional arguments when creating an application directory.
+type createOptions struct {
+	mode      os.FileMode
+	exact     bool
+	fallbacks []string
+}
+
+// exactModeOption forces the exact mode of a created application directory, regardless of the umask.
+type exactModeOption struct{}
+
+// modeOption associates a specific mode for the creation of an application directory.
+type modeOption struct {
+	Mode os.FileMode
+}
+
+// tempFallbackOption associates a fallback directory for the creation of the temp directory.
+type tempFallbackOption struct {
+	Path string
+}
+
+// scratchPool holds the idle scratch directories available for reuse.
+type scratchPool struct {
+	mutex sync.Mutex
+	idle  []string
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
 //======================================================================================================================
 // region Public Types
 //======================================================================================================================
@@ -33,8 +153,73 @@ type AppDirs struct {
 	temp      *Dir
 	workspace *Dir
 
+	tempBase string // fallback directory chosen by CreateTemp, reset by Assign
+
+	persistentTemp *Dir // temp directory preserved between system reboots
+
+	workspaces map[string]*Dir  // named workspace directories, keyed by name
+	custom     map[DirType]*Dir // directories of custom types, keyed by type
+
 	keywords        map[string]string //TODO: add make to init?
 	keywordsReverse map[string]string
+	aliasPriority   []string // aliases preferred for reverse substitution, in order of precedence
+	fallbackApp     string   // application name to resolve unassigned default keywords, empty when disabled
+
+	scratch scratchPool // reusable scratch directories within the temp directory
+}
+
+// CreateOption defines an optional argument for creating an application directory on disk.
+type CreateOption interface {
+	applyCreate(*createOptions)
+}
+
+// Entry describes a configured application directory, including its type, path, and aliases (keywords).
+type Entry struct {
+	// Type indicates the type of directory.
+	Type DirType
+
+	// Path is the absolute path associated with the directory.
+	Path string
+
+	// Aliases holds a copy of the keywords associated with the directory.
+	Aliases []string
+}
+
+// Resolver defines the key methods of AppDirs to resolve and parameterize application paths. Consumers can depend on
+// Resolver instead of AppDirs to simplify the injection of fakes during testing.
+type Resolver interface {
+	// Cache retrieves the current cache directory.
+	Cache() string
+
+	// Config retrieves the current config directory.
+	Config() string
+
+	// Home retrieves the current home directory.
+	Home() string
+
+	// MakeAbsolute returns the absolute path for a given input, replacing supported keywords.
+	MakeAbsolute(basePath string, input string) string
+
+	// MakeRelative returns the path for a given input relative to a base path, replacing supported keywords.
+	MakeRelative(basePath string, input string) string
+
+	// Parameterize replaces matched path segments of the input with their keyword.
+	Parameterize(basePath string, input string) string
+
+	// Temp retrieves the current temp directory.
+	Temp() string
+
+	// Workspace retrieves the current workspace directory.
+	Workspace() string
+}
+
+// Warning describes a default or fallback value applied during the initialization of an application directory.
+type Warning struct {
+	// DirType indicates the type of directory the warning applies to.
+	DirType DirType
+
+	// Message describes the applied default or fallback value.
+	Message string
 }
 
 //======================================================================================================================
@@ -45,9 +230,228 @@ type AppDirs struct {
 // region Private Functions
 //======================================================================================================================
 
+// applyCreate forces the exact mode of a created application directory, regardless of the umask.
+func (o exactModeOption) applyCreate(opts *createOptions) {
+	opts.exact = true
+}
+
+// applyCreate associates a specific mode for the creation of an application directory.
+func (o modeOption) applyCreate(opts *createOptions) {
+	opts.mode = o.Mode
+}
+
+// applyCreate associates a fallback directory for the creation of the temp directory.
+func (o tempFallbackOption) applyCreate(opts *createOptions) {
+	opts.fallbacks = append(opts.fallbacks, o.Path)
+}
+
+// newCreateOptions initializes the options for creating a directory of the given type. The mode defaults to the
+// default mode of the directory type.
+func newCreateOptions(dirType DirType, opts []CreateOption) createOptions {
+	options := createOptions{mode: dirType.DefaultMode()}
+	for _, o := range opts {
+		o.applyCreate(&options)
+	}
+	return options
+}
+
+// applyAliasPriority updates the reverse keyword map with the configured alias priority. For each path, the alias
+// that appears first in the priority order takes precedence over any other alias associated with the same path.
+func (a *AppDirs) applyAliasPriority() {
+	for i := len(a.aliasPriority) - 1; i >= 0; i-- {
+		alias := a.aliasPriority[i]
+		if path, found := a.keywords[alias]; found {
+			a.keywordsReverse[path] = alias
+		}
+	}
+}
+
+// defaultKeywordPath returns the default path of the unassigned directory type that has alias as one of its default
+// aliases, see SetDefaultFallback. It returns an empty string if the fallback is disabled, if the alias is not a
+// default alias of an unassigned directory type, or if the default path cannot be resolved.
+func (a *AppDirs) defaultKeywordPath(alias string) string {
+	if a.fallbackApp == "" {
+		return ""
+	}
+	for _, t := range registeredDirTypes() {
+		if a.dir(t) == nil && exists(defaultAliases(t), alias) {
+			if d, e := NewDir(t, a.fallbackApp); e == nil {
+				return d.Path()
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// dir retrieves the application directory of a specific type. It returns nil if the directory is not set.
+func (a *AppDirs) dir(dirType DirType) *Dir {
+	switch dirType {
+	case Cache:
+		return a.cache
+	case Config:
+		return a.config
+	case Home:
+		return a.home
+	case Temp:
+		return a.temp
+	case Workspace:
+		return a.workspace
+	case PersistentTemp:
+		return a.persistentTemp
+	}
+	return a.custom[dirType]
+}
+
+// dirByAlias retrieves the application directory associated with an alias, including the named workspaces. It returns
+// nil if no directory is associated with the alias.
+func (a *AppDirs) dirByAlias(alias string) *Dir {
+	for _, t := range registeredDirTypes() {
+		if d := a.dir(t); d != nil && exists(d.aliases, alias) {
+			return d
+		}
+	}
+	for _, d := range a.workspaces {
+		if exists(d.aliases, alias) {
+			return d
+		}
+	}
+	return nil
+}
+
+// emptyDir removes all files and subdirectories within a directory, leaving the directory itself in place.
+func emptyDir(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if e := os.RemoveAll(filepath.Join(path, entry.Name())); e != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// expandText replaces all keywords within a text with their replacement values. A keyword is only replaced when it
+// represents a full token, delimited by whitespace, quotes, path separators, or the boundaries of the text.
+func (a *AppDirs) expandText(text string) string {
+	var b strings.Builder
+	var token strings.Builder
+
+	flush := func() {
+		if s, found := a.keywords[token.String()]; found && s != "" {
+			b.WriteString(s)
+		} else {
+			b.WriteString(token.String())
+		}
+		token.Reset()
+	}
+
+	for _, r := range text {
+		switch {
+		case r == '"' || r == '\'' || r == '/' || r == os.PathSeparator || unicode.IsSpace(r):
+			flush()
+			b.WriteRune(r)
+		default:
+			token.WriteRune(r)
+		}
+	}
+	flush()
+
+	return b.String()
+}
+
+// expandFallback resolves a segment using the shell syntax for fallbacks, "${NAME:-default}" or "${NAME:+alt}". The
+// first form is replaced with the value of the keyword "${NAME}" or "$NAME" if it can be resolved, and with default
+// otherwise. The second form is replaced with alt if the keyword can be resolved, and with an empty string otherwise.
+// It returns whether the value of the keyword was used, and false for ok if the segment does not use the syntax.
+func (a *AppDirs) expandFallback(segment string) (path string, keyword bool, ok bool) {
+	if !strings.HasPrefix(segment, "${") || !strings.HasSuffix(segment, "}") {
+		return "", false, false
+	}
+	inner := segment[2 : len(segment)-1]
+	i := strings.Index(inner, ":")
+	if i < 0 || i+1 >= len(inner) || !isVarName(inner[:i]) {
+		return "", false, false
+	}
+	op, word := inner[i+1], filepath.FromSlash(inner[i+2:])
+
+	var value string
+	for _, k := range []string{"${" + inner[:i] + "}", "$" + inner[:i]} {
+		if value = a.keywords[k]; value == "" {
+			value = a.defaultKeywordPath(k)
+		}
+		if value != "" {
+			break
+		}
+	}
+
+	switch {
+	case op == '-' && value != "":
+		return value, true, true
+	case op == '-':
+		return word, false, true
+	case op == '+' && value != "":
+		return word, false, true
+	case op == '+':
+		return "", false, true
+	}
+	return "", false, false
+}
+
+// expandVars replaces the variables "$NAME" and "${NAME}" within input with their values, similar to os.Expand. The
+// variables for which mapping returns false are kept as written, e.g. "$NAME" remains "$NAME".
+func expandVars(input string, mapping func(name string) (string, bool)) string {
+	var b strings.Builder
+	i := 0
+	for j := 0; j < len(input); j++ {
+		if input[j] != '$' || j+1 >= len(input) {
+			continue
+		}
+		b.WriteString(input[i:j])
+		name, w := shellName(input[j+1:])
+		switch {
+		case name == "" && w > 0:
+			// invalid syntax, the characters are removed consistent with os.Expand
+		case name == "":
+			b.WriteByte(input[j]) // the "$" is not followed by a name, keep it as literal
+		default:
+			if value, found := mapping(name); found {
+				b.WriteString(value)
+			} else {
+				b.WriteString(input[j : j+1+w])
+			}
+		}
+		j += w
+		i = j + 1
+	}
+	b.WriteString(input[i:])
+	return b.String()
+}
+
+// exportVars returns the environment variables defined by the keywords of the application directories, keyed by
+// variable name. The names are sorted alphabetically. Keywords that are not valid variable names are omitted.
+func (a *AppDirs) exportVars() (names []string, vars map[string]string) {
+	vars = make(map[string]string, len(a.keywordsReverse))
+	names = make([]string, 0, len(a.keywordsReverse))
+	for path, alias := range a.keywordsReverse {
+		if name := aliasName(alias); isVarName(name) {
+			vars[name] = path
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, vars
+}
+
 func init() {
 	if runtime.GOOS != "windows" {
 		defaultHome = append(defaultHome, "~")
+	} else {
+		// use the native Windows variables as primary alias, so Parameterize produces a meaningful result
+		defaultHome = append([]string{"%USERPROFILE%"}, defaultHome...)
+		defaultTemp = append(defaultTemp, "%TEMP%", "%TMP%")
 	}
 }
 
@@ -71,195 +475,1389 @@ func (a *AppDirs) initKeywords() {
 	if a.workspace != nil {
 		dirs = append(dirs, a.workspace)
 	}
+	if a.persistentTemp != nil {
+		dirs = append(dirs, a.persistentTemp)
+	}
+	for _, t := range registeredDirTypes() {
+		if d, found := a.custom[t]; found {
+			dirs = append(dirs, d)
+		}
+	}
 
 	for _, d := range dirs {
-		for i, alias := range d.Aliases() {
+		for _, alias := range d.Aliases() {
+			a.keywords[alias] = d.Path()
+		}
+		if alias := d.CanonicalAlias(); alias != "" {
+			a.keywordsReverse[d.Path()] = alias
+		}
+	}
+
+	// add the named workspaces in alphabetical order, without overriding the reverse keywords of the main directories
+	names := make([]string, 0, len(a.workspaces))
+	for name := range a.workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		d := a.workspaces[name]
+		for _, alias := range d.Aliases() {
 			a.keywords[alias] = d.Path()
-			if i == 0 {
-				a.keywordsReverse[d.Path()] = alias
-			}
+		}
+		if _, found := a.keywordsReverse[d.Path()]; !found && d.CanonicalAlias() != "" {
+			a.keywordsReverse[d.Path()] = d.CanonicalAlias()
 		}
 	}
+
+	a.applyAliasPriority()
 }
 
-//======================================================================================================================
-// endregion
-//======================================================================================================================
+// isKeywordRef returns whether a path segment is formatted as a keyword reference. A segment is considered a keyword
+// reference if it uses braces with a non-empty name (e.g. "${CACHE}"), or if it starts with a "$" directly followed by
+// a valid variable name (e.g. "$CACHE"). Other segments containing a "$", such as "$5", "price$", or "$file.txt", are
+// considered literal file names.
+func isKeywordRef(segment string) bool {
+	if strings.HasPrefix(segment, "${") && strings.HasSuffix(segment, "}") {
+		return len(segment) > 3
+	}
+	return strings.HasPrefix(segment, "$") && isVarName(segment[1:])
+}
 
-//======================================================================================================================
-// region Public Functions
-//======================================================================================================================
+// isNameChar returns true if c is an ASCII letter, digit, or underscore, which are allowed in a variable name.
+func isNameChar(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
 
-// NewAppDirs initializes a AppDirs type with default values for the application-specific cache, config, home, temp,
-// and workspace directories. Default aliases are added to enable keyword expansion. The keywords follow POSIX string
-// expansion rules, using "$" as sigil and optional braces. The following keywords are supported: $HOME, $CACHE, $PWD,
-// $TEMP, $TMP, $TMPDIR, $TEMPDIR, and $workspaceRoot. The special character '~' is expanded to the home directory
-// (unless the OS is Windows).
-func NewAppDirs(appName string) (dirs *AppDirs, err error) {
+// isPathChar returns whether r is typically part of a path, such as a letter, digit, separator, or one of the
+// characters ".", "-", "_", "~", "$", and "%".
+func isPathChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '/' || r == os.PathSeparator ||
+		strings.ContainsRune(".-_~$%", r)
+}
+
+// isVarName returns whether name is a valid name for a shell variable, consisting of letters, digits, and underscores
+// and not starting with a digit.
+func isVarName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && !(i > 0 && r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// isWritable validates if a file can be created within the provided directory.
+func isWritable(path string) bool {
+	f, e := os.CreateTemp(path, ".writable-*")
+	if e != nil {
+		return false
+	}
+	e = f.Close()
+	_ = os.Remove(f.Name())
+	return e == nil
+}
+
+// makeAbsolute returns the absolute path for a given input and the aliases substituted in order of appearance. The
+// input is split into segments using sep. See MakeAbsolute for more details.
+func (a *AppDirs) makeAbsolute(basePath string, input string, sep rune) (path string, used []string) {
+	segments := splitSegments(input, sep)
+	var result string
+	used = make([]string, 0)
+
+	for i, segment := range segments {
+		// skip current directory segments, which would otherwise disrupt the expansion of subsequent keywords
+		if segment == "." && len(segments) > 1 {
+			continue
+		}
+
+		s := a.keywords[segment]
+		if s == "" {
+			s = a.defaultKeywordPath(segment)
+		}
+		if segment == "~" && i > 0 {
+			s = "" // only expand the home directory as first path component
+		}
+		if s != "" {
+			result = filepath.Join(result, s)
+			used = append(used, segment)
+		} else if v, keyword, ok := a.expandFallback(segment); ok {
+			result = filepath.Join(result, v)
+			if keyword {
+				used = append(used, segment)
+			}
+		} else if v := a.windowsVar(segment); v != "" {
+			result = filepath.Join(result, v)
+			used = append(used, segment)
+		} else {
+			if runtime.GOOS == "windows" && i == 0 && strings.EqualFold(filepath.VolumeName(segment), segment) {
+				segment = fmt.Sprintf("%s%c", segment, filepath.Separator)
+			}
+			result = filepath.Join(result, segment)
+		}
+	}
+
+	// prepend the leading `/` if needed
+	if strings.HasPrefix(input, string(sep)) && runtime.GOOS != "windows" && !filepath.IsAbs(result) {
+		result = string(os.PathSeparator) + result
+	}
+
+	return AbsPath(basePath, result), used
+}
+
+// managedPath returns the absolute path of rel within the directory of the given type. An error is returned if the
+// directory is not set, if rel is an absolute path, or if rel escapes the directory.
+func (a *AppDirs) managedPath(dirType DirType, rel string) (string, error) {
+	d := a.dir(dirType)
+	if d == nil || d.Path() == "" {
+		return "", fmt.Errorf("%s directory is not set", dirType.String())
+	}
+	if filepath.IsAbs(rel) {
+		return "", fmt.Errorf("expected a relative path within %s directory: %s", dirType.String(), rel)
+	}
+	path := filepath.Join(d.Path(), rel)
+	if r, ok := relWithin(d.Path(), path); !ok || r == "." {
+		return "", fmt.Errorf("path escapes %s directory: %s", dirType.String(), rel)
+	}
+	return path, nil
+}
+
+// newAppDirsVerbose initializes a AppDirs type for a validated application name, see NewAppDirsVerbose. The options
+// are applied to each directory.
+func newAppDirsVerbose(appName string, opts ...Option) (dirs *AppDirs, warnings []Warning, err error) {
 	var d AppDirs
 
-	cache, e := NewDir(Cache, appName)
+	cache, e := NewDir(Cache, appName, opts...)
 	if e != nil {
-		return nil, e
+		fallback := filepath.Join(os.TempDir(), appName+"-cache")
+		if cache, e = NewDir(Cache, appName, append(opts, WithPath(fallback))...); e != nil {
+			return nil, nil, e
+		}
+		warnings = append(warnings, Warning{
+			DirType: Cache,
+			Message: fmt.Sprintf("cannot resolve user cache directory, using fallback: %s", fallback),
+		})
 	}
 	d.cache = cache
 
-	config, e := NewDir(Config, appName)
+	config, e := NewDir(Config, appName, opts...)
 	if e != nil {
-		return nil, e
+		var w *Warning
+		if config, w, e = newRootDir(Config, appName, append(opts, WithRootConfig())); e != nil {
+			return nil, nil, e
+		}
+		if w != nil {
+			warnings = append(warnings, *w)
+		}
+		warnings = append(warnings, Warning{
+			DirType: Config,
+			Message: fmt.Sprintf("cannot resolve user config directory, using fallback: %s", config.Path()),
+		})
 	}
 	d.config = config
 
-	home, e := NewDir(Home, appName)
+	home, e := NewDir(Home, appName, opts...)
 	if e != nil {
-		return nil, e
+		return nil, nil, e
 	}
 	d.home = home
 
-	temp, e := NewDir(Temp, appName)
+	temp, e := NewDir(Temp, appName, opts...)
 	if e != nil {
-		return nil, e
+		return nil, nil, e
 	}
 	d.temp = temp
 
-	workspace, e := NewDir(Workspace, appName)
+	persistentTemp, e := NewDir(PersistentTemp, appName, opts...)
 	if e != nil {
-		return nil, e
+		return nil, nil, e
+	}
+	d.persistentTemp = persistentTemp
+
+	workspace, w, e := newRootDir(Workspace, appName, opts)
+	if e != nil {
+		return nil, nil, e
+	}
+	if w != nil {
+		warnings = append(warnings, *w)
 	}
 	d.workspace = workspace
 
 	d.initKeywords()
 
-	return &d, nil
-}
+	// warn about directories sharing the same path, such as the default config and workspace directories
+	collisions := d.Collisions()
+	for _, entry := range d.Entries() {
+		if types := collisions[entry.Path]; len(types) > 1 && types[0] == entry.Type {
+			names := make([]string, 0, len(types)-1)
+			for _, t := range types[1:] {
+				names = append(names, t.String())
+			}
+			warnings = append(warnings, Warning{
+				DirType: entry.Type,
+				Message: fmt.Sprintf("directory shares its path with %s: %s", strings.Join(names, ", "), entry.Path),
+			})
+		}
+	}
 
-// Assign initializes a new application-specific directory and updates the internal keyword map to enable
-// parameterization of paths. Default aliases are added when no aliases are provided. The full keyword map is updated
-// when an existing entry is updated, otherwise the new keywords are appended. Assign does not check for potential
-// duplicate keywords.
-func (a *AppDirs) Assign(d Dir) {
-	var updated bool
-	switch d.DirType() {
-	case Cache:
-		updated = a.cache != nil
-		a.cache = &d
+	return &d, warnings, nil
+}
 
-	case Config:
-		updated = a.config != nil
-		a.config = &d
+// newRootDir initializes a directory of the given type that defaults to the workspace root, such as Config and
+// Workspace. It falls back to the current working directory when the workspace root cannot be identified, returning a
+// warning that describes the fallback.
+func newRootDir(dirType DirType, appName string, opts []Option) (*Dir, *Warning, error) {
+	dir, e := NewDir(dirType, appName, opts...)
+	if e == nil {
+		return dir, nil, nil
+	}
 
-	case Home:
-		updated = a.home != nil
-		a.home = &d
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, e
+	}
+	if dir, err = NewDir(dirType, appName, append(opts, WithPath(wd))...); err != nil {
+		return nil, nil, err
+	}
+	w := &Warning{
+		DirType: dirType,
+		Message: fmt.Sprintf("cannot identify workspace root, using working directory: %s", wd),
+	}
+	return dir, w, nil
+}
 
-	case Temp:
-		updated = a.temp != nil
-		a.temp = &d
+// parameterize replaces matched path segments of the input with their parameter alias. The result is cleaned and
+// made relative to basePath if applicable when clean is set. See Parameterize for more details.
+func (a *AppDirs) parameterize(basePath string, input string, clean bool) (path string) {
+	// create an list of all key/value pairs, sorted by key length in descending order
+	type item struct {
+		key   string
+		value string
+	}
+	ordered := make([]item, 0, len(a.keywordsReverse))
+	for k, v := range a.keywordsReverse {
+		ordered = append(ordered, item{key: k, value: v})
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return len(ordered[i].key) > len(ordered[j].key)
+	})
+
+	// substitute the paths with their keyword
+	for _, o := range ordered {
+		input = replacePath(input, o.key, o.value)
+	}
+
+	// remove any trailing '/'
+	input = strings.TrimSuffix(input, string(os.PathSeparator))
+
+	if filepath.IsAbs(input) {
+		return input
+	}
+	if !clean {
+		// strip the base path without resolving any parent directory references
+		if prefix := basePath + string(os.PathSeparator); basePath != "" && strings.HasPrefix(input, prefix) {
+			return input[len(prefix):]
+		}
+		return input
+	}
+	result, err := filepath.Rel(basePath, input)
+	if err != nil {
+		return filepath.Clean(input)
+	}
+	return result
+}
+
+// relWithin returns the path relative to the provided base directory. It returns false if path is not within base.
+func relWithin(base string, path string) (string, bool) {
+	rel, e := filepath.Rel(filepath.Clean(base), filepath.Clean(path))
+	if e != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// removeTemp removes the configured temp dir as described by RemoveTempRetry. The caller is expected to hold the lock
+// of the subdirectory.
+func (a *AppDirs) removeTemp(subdir string, attempts int, delay time.Duration) (err error) {
+	// validate the configured temp directory is valid and safe
+	if a.temp.Path() == "" {
+		return fmt.Errorf("temp directory is not configured correctly")
+	}
+	tmp := filepath.Clean(os.TempDir())
+	if a.tempBase != "" && filepath.Dir(a.temp.Path()) == a.tempBase && exists(tempFallbacks, a.tempBase) {
+		tmp = a.tempBase
+	}
+	current := filepath.Join(a.temp.Path(), subdir)
+
+	if !strings.HasPrefix(current, tmp) {
+		return fmt.Errorf("temp directory is considered unsafe")
+	}
+
+	if current == tmp {
+		return fmt.Errorf("expected a subdirectory within the temp directory")
+	}
+
+	// validate the temp directory does not resolve to a location outside of the system's temp directory
+	resolvedTmp, resolved := resolvePath(tmp), resolvePath(current)
+	if _, ok := relWithin(resolvedTmp, resolved); !ok || resolved == resolvedTmp {
+		return fmt.Errorf("temp directory is considered unsafe")
+	}
+
+	// remove the temp dir if it exists, retrying on transient errors
+	for i := 1; ; i++ {
+		e := removeAll(current)
+		if e == nil {
+			// remove the vendor directory of a namespaced application if it's empty, e.g. '/tmp/Vendor'
+			if parent := filepath.Dir(current); subdir == "" && parent != tmp && filepath.Dir(parent) == tmp {
+				_ = os.Remove(parent)
+			}
+			return nil
+		}
+		if i >= attempts || !isTransient(e) {
+			return e
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// replacePath replaces each occurrence of the path old within input with new, similar to strings.ReplaceAll. Unlike
+// strings.ReplaceAll, an occurrence is only replaced if it covers complete path segments. The occurrence should be
+// followed by a path separator or the end of the input, and should not be preceded by a character that is part of a
+// path. For example, "/tmp/app" is replaced in "/tmp/app/x", but not in "/tmp/app-old" or "/var/tmp/app".
+func replacePath(input string, old string, new string) string {
+	if old == "" {
+		return input
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(input, old)
+		if i < 0 {
+			break
+		}
+		end := i + len(old)
+		prev, _ := utf8.DecodeLastRuneInString(input[:i])
+		before := i == 0 || !isPathChar(prev)
+		after := end == len(input) || input[end] == '/' || input[end] == os.PathSeparator ||
+			strings.HasSuffix(old, string(os.PathSeparator))
+		b.WriteString(input[:i])
+		if before && after {
+			b.WriteString(new)
+		} else {
+			b.WriteString(old)
+		}
+		input = input[end:]
+	}
+	b.WriteString(input)
+	return b.String()
+}
+
+// resolvePath evaluates any symbolic links within a path. When the path does not exist, the nearest existing ancestor
+// is evaluated instead and the remainder of the path is appended to the result.
+func resolvePath(path string) string {
+	path = filepath.Clean(path)
+	var rest []string
+	for {
+		resolved, e := filepath.EvalSymlinks(path)
+		if e == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...)
+		}
+
+		parent := filepath.Dir(path)
+		if parent == path {
+			return filepath.Join(append([]string{path}, rest...)...)
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+// shellName returns the name of the variable at the start of input, which follows a "$", and the number of bytes
+// consumed. It follows the syntax of os.Expand: the name is either enclosed in braces, a single special character such
+// as "$1", or a sequence of letters, digits, and underscores. An empty name and a positive width indicate an invalid
+// syntax, such as "${}".
+func shellName(input string) (name string, w int) {
+	isSpecial := func(c byte) bool { return strings.IndexByte("*#$@!?-0123456789", c) >= 0 }
+	if input[0] == '{' {
+		if len(input) > 2 && isSpecial(input[1]) && input[2] == '}' {
+			return input[1:2], 3
+		}
+		for i := 1; i < len(input); i++ {
+			if input[i] == '}' {
+				if i == 1 {
+					return "", 2
+				}
+				return input[1:i], i + 1
+			}
+		}
+		return "", 1
+	}
+	if isSpecial(input[0]) {
+		return input[0:1], 1
+	}
+	var i int
+	for i < len(input) && isNameChar(input[i]) {
+		i++
+	}
+	return input[:i], i
+}
+
+// splitSegments splits the input into path segments separated by sep, similar to strings.Split. Separators enclosed
+// in braces, such as in "${CACHE:-/tmp/cache}", do not split the input.
+func splitSegments(input string, sep rune) []string {
+	var segments []string
+	start := 0
+	for i := 0; i < len(input); i++ {
+		if strings.HasPrefix(input[i:], "${") {
+			if end := strings.IndexByte(input[i:], '}'); end >= 0 {
+				i += end
+				continue
+			}
+		}
+		if r, size := utf8.DecodeRuneInString(input[i:]); r == sep {
+			segments = append(segments, input[start:i])
+			start = i + size
+		}
+	}
+	return append(segments, input[start:])
+}
+
+// tempLock acquires the locks guarding the temp operations on a subdirectory of the temp directory root and returns a
+// function to release them. Operations on the entire temp directory, identified by an empty subdir, hold the lock of
+// the temp directory exclusively. Operations on a subdirectory share the lock of the temp directory and hold the lock
+// of the subdirectory exclusively. As such, operations on the same subdirectory are serialized with each other and
+// with operations on the entire temp directory, while operations on different subdirectories likely proceed in
+// parallel.
+func tempLock(root string, subdir string) (unlock func()) {
+	stripe := func(path string) uint32 {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(filepath.Clean(path)))
+		return h.Sum32() % tempLockStripes
+	}
+
+	tree := &tempTreeLocks[stripe(root)]
+	path := filepath.Join(root, subdir)
+	if path == filepath.Clean(root) {
+		tree.Lock()
+		return tree.Unlock
+	}
+
+	tree.RLock()
+	lock := &tempLocks[stripe(path)]
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		tree.RUnlock()
+	}
+}
+
+// windowsVar resolves a segment using the Windows syntax for variables, such as "%SYSTEMROOT%". The name is matched
+// against the keyword "$NAME" first, and the environment variable NAME second. It returns an empty string if the OS is
+// not Windows, the segment does not use the Windows syntax, or the name cannot be resolved.
+func (a *AppDirs) windowsVar(segment string) string {
+	if runtime.GOOS != "windows" || len(segment) < 3 || !strings.HasPrefix(segment, "%") ||
+		!strings.HasSuffix(segment, "%") {
+		return ""
+	}
+	name := segment[1 : len(segment)-1]
+	if s := a.keywords["$"+name]; s != "" {
+		return s
+	}
+	return os.Getenv(name)
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Public Functions
+//======================================================================================================================
+
+// NewAppDirs initializes a AppDirs type with default values for the application-specific cache, config, home, temp,
+// and workspace directories. Default aliases are added to enable keyword expansion. The keywords follow POSIX string
+// expansion rules, using "$" as sigil and optional braces. The following keywords are supported: $HOME, $CACHE, $PWD,
+// $TEMP, $TMP, $TMPDIR, $TEMPDIR, $PERSISTENT_TEMP, and $workspaceRoot. The special character '~' is expanded to the
+// home directory (unless the OS is Windows). On Windows, the home directory is associated with %USERPROFILE% instead,
+// and the temp directory is associated with %TEMP% and %TMP% too.
+func NewAppDirs(appName string) (dirs *AppDirs, err error) {
+	dirs, _, err = NewAppDirsVerbose(appName)
+	return dirs, err
+}
+
+// NewAppDirsForUser initializes a AppDirs type similar to NewAppDirs, deriving the directories from the home directory
+// of the given user instead of the environment of the current process. This enables a privileged service to set up
+// the directories on behalf of another user. The cache and config directories follow the conventions of the operating
+// system, e.g. '~/.cache' and '~/.config' on Unix, '~/Library/Caches' and '~/Library/Application Support' on macOS,
+// and '%USERPROFILE%\AppData\Local' and '%USERPROFILE%\AppData\Roaming' on Windows. The home and workspace
+// directories are set to the user's home directory. On Windows, the temp and persistent temp directories are derived
+// from the user's profile too, other systems use the system-wide temp directories. An error is returned if the user
+// has no home directory, or if the home directory is a relative path.
+func NewAppDirsForUser(appName string, u *user.User) (dirs *AppDirs, err error) {
+	if e := validateAppName(appName, false); e != nil {
+		return nil, e
+	}
+	if u == nil || u.HomeDir == "" {
+		return nil, fmt.Errorf("cannot initialize directories, user has no home directory")
+	}
+	if !filepath.IsAbs(u.HomeDir) {
+		return nil, fmt.Errorf("%w: %s", ErrRelativePath, u.HomeDir)
+	}
+
+	paths := map[DirType]string{
+		Cache:          filepath.Join(cacheDirFor(runtime.GOOS, u.HomeDir), appName),
+		Config:         filepath.Join(configDirFor(runtime.GOOS, u.HomeDir), appName),
+		Home:           u.HomeDir,
+		Temp:           filepath.Join(tempDir(runtime.GOOS), appName),
+		PersistentTemp: persistentTempPath(runtime.GOOS, appName),
+		Workspace:      u.HomeDir,
+	}
+	if runtime.GOOS == "windows" {
+		temp := filepath.Join(u.HomeDir, "AppData", "Local", "Temp")
+		paths[Temp] = filepath.Join(temp, appName)
+		paths[PersistentTemp] = filepath.Join(temp, appName+"-persistent")
+	}
+
+	created := make(map[DirType]*Dir, len(paths))
+	for _, t := range []DirType{Cache, Config, Home, Temp, PersistentTemp, Workspace} {
+		d, e := NewDir(t, appName, WithPath(paths[t]))
+		if e != nil {
+			return nil, e
+		}
+		created[t] = d
+	}
+
+	d := AppDirs{
+		cache:          created[Cache],
+		config:         created[Config],
+		home:           created[Home],
+		temp:           created[Temp],
+		persistentTemp: created[PersistentTemp],
+		workspace:      created[Workspace],
+	}
+	d.initKeywords()
+
+	return &d, nil
+}
+
+// NewAppDirsFromEnv initializes a AppDirs type similar to NewAppDirs, overriding the directories defined by environment
+// variables with the given prefix. The variables are named after the directory type in upper case, e.g. 'APP_CACHE',
+// 'APP_CONFIG', 'APP_HOME', 'APP_TEMP', 'APP_WORKSPACE', and 'APP_PERSISTENT_TEMP' for the prefix 'APP'. Directories
+// without a variable, or with an empty variable, retain their default path. The variables should define absolute
+// paths, an error wrapping ErrRelativePath is returned otherwise.
+func NewAppDirsFromEnv(appName string, prefix string) (dirs *AppDirs, err error) {
+	dirs, err = NewAppDirs(appName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range []DirType{Cache, Config, Home, Temp, Workspace, PersistentTemp} {
+		name := prefix + "_" + strings.ToUpper(strings.ReplaceAll(t.String(), "-", "_"))
+		path := os.Getenv(name)
+		if path == "" {
+			continue
+		}
+
+		d, e := NewDir(t, appName, WithPath(path))
+		if e != nil {
+			return nil, fmt.Errorf("cannot initialize %s directory from %s: %w", t.String(), name, e)
+		}
+		dirs.Assign(*d)
+	}
+
+	return dirs, nil
+}
+
+// NewAppDirsNamespaced initializes a AppDirs type similar to NewAppDirs, using a vendor and product name as nested
+// application name. The application-specific directories are nested as 'Vendor/Product' on all platforms, using the
+// platform-specific path separator, e.g. '%LocalAppData%\Vendor\Product' for the cache directory on Windows. When
+// running a compiled binary, the product name is matched against the binary's name to identify the workspace.
+func NewAppDirsNamespaced(vendor string, product string) (dirs *AppDirs, err error) {
+	for _, name := range []string{vendor, product} {
+		if e := validateAppName(name, false); e != nil {
+			return nil, e
+		}
+	}
+
+	dirs, _, err = newAppDirsVerbose(filepath.Join(vendor, product), WithNestedName())
+	return dirs, err
+}
+
+// NewAppDirsVerbose initializes a AppDirs type similar to NewAppDirs. In addition, it returns a warning for each
+// default or fallback value applied during the initialization. The cache directory falls back to a sibling of the
+// application's temp directory when the user-specific cache directory cannot be resolved, e.g. '/tmp/app-cache'. The
+// config directory falls back to the workspace root when the user-specific config directory cannot be resolved. The
+// workspace directory falls back to the current working directory when no workspace root can be identified. A warning
+// is returned too for directories sharing the same path, see Collisions. The writability of the temp directory is
+// verified by CreateTemp instead.
+func NewAppDirsVerbose(appName string) (dirs *AppDirs, warnings []Warning, err error) {
+	if e := validateAppName(appName, false); e != nil {
+		return nil, nil, e
+	}
+
+	return newAppDirsVerbose(appName)
+}
+
+// Absolutize returns the parameterized absolute form of a path relative to the base path, which is the inverse of
+// MakeRelative. The input is joined with the base path and any keywords are expanded, after which the matched path
+// segments are replaced with their canonical alias, e.g. "../.cache/app/file" becomes "$CACHE/file" for a base path
+// within the home directory. This produces a portable representation suitable for storing in configuration files.
+// The input is returned as parameterized absolute path if it is absolute already. Absolutize calls filepath.Clean on
+// the result.
+func (a *AppDirs) Absolutize(basePath string, relInput string) string {
+	return a.Parameterize(basePath, a.MakeAbsolute(basePath, relInput))
+}
+
+// AcquireScratch hands out an empty scratch directory within the application's temp directory. Idle scratch
+// directories are reused, new ones are created as needed. The temp directory is created if needed. Call release once
+// done to empty the scratch directory and return it to the pool. The pool retains a limited number of idle
+// directories, surplus directories are removed on release.
+func (a *AppDirs) AcquireScratch() (path string, release func(), err error) {
+	if e := a.CreateTemp(); e != nil {
+		return "", nil, e
+	}
+
+	// reuse an idle scratch directory that still exists, or create a new one
+	a.scratch.mutex.Lock()
+	for path == "" && len(a.scratch.idle) > 0 {
+		candidate := a.scratch.idle[len(a.scratch.idle)-1]
+		a.scratch.idle = a.scratch.idle[:len(a.scratch.idle)-1]
+		if info, e := os.Stat(candidate); e == nil && info.IsDir() {
+			path = candidate
+		}
+	}
+	a.scratch.mutex.Unlock()
+	if path == "" {
+		if path, err = os.MkdirTemp(a.Temp(), scratchPattern); err != nil {
+			return "", nil, fmt.Errorf("cannot create scratch directory: %w", err)
+		}
+	}
+
+	// empty the directory and return it to the pool on release, removing it when the pool is full or cleaning fails
+	var once sync.Once
+	release = func() {
+		once.Do(func() {
+			a.scratch.mutex.Lock()
+			defer a.scratch.mutex.Unlock()
+			if len(a.scratch.idle) < maxScratchDirs && emptyDir(path) == nil {
+				a.scratch.idle = append(a.scratch.idle, path)
+				return
+			}
+			_ = os.RemoveAll(path)
+		})
+	}
+
+	return path, release, nil
+}
+
+// AddWorkspace registers an additional, named workspace directory to support multi-root workspaces. The path must be
+// absolute. The workspace is associated with the aliases "$workspaceRoot:name" and "${workspaceRoot:name}", which are
+// recognized by MakeAbsolute and Parameterize. An existing workspace with the same name is replaced.
+func (a *AppDirs) AddWorkspace(name string, path string) error {
+	if name == "" {
+		return fmt.Errorf("cannot add workspace without name")
+	}
+
+	aliases := []string{fmt.Sprintf("$workspaceRoot:%s", name), fmt.Sprintf("${workspaceRoot:%s}", name)}
+	d, e := NewDir(Workspace, name, WithPath(path), WithAliases(aliases))
+	if e != nil {
+		return e
+	}
+
+	if a.workspaces == nil {
+		a.workspaces = make(map[string]*Dir)
+	}
+	a.workspaces[name] = d
+	a.initKeywords()
+
+	return nil
+}
+
+// ApplyXDGUserDirs assigns the paths loaded by LoadXDGUserDirs to the directory types mapped by their variable name,
+// e.g. "XDG_DOCUMENTS_DIR" to a custom type registered with RegisterDirType. The aliases of a directory that is
+// already set are retained, otherwise the default aliases of the type are used. Variables without a loaded path
+// are ignored.
+func (a *AppDirs) ApplyXDGUserDirs(userDirs map[string]string, types map[string]DirType) {
+	for name, dirType := range types {
+		path, found := userDirs[name]
+		if !found {
+			continue
+		}
+
+		aliases := DefaultAliases(dirType)
+		if d := a.dir(dirType); d != nil {
+			aliases = d.Aliases()
+		}
+		a.Assign(Dir{dirType: dirType, path: path, aliases: aliases})
+	}
+}
+
+// Assign initializes a new application-specific directory and updates the internal keyword map to enable
+// parameterization of paths. Default aliases are added when no aliases are provided. The full keyword map is updated
+// when an existing entry is updated, otherwise the new keywords are appended. The path of the directory is normalized
+// to exclude any trailing separator. Assign does not check for potential duplicate keywords.
+func (a *AppDirs) Assign(d Dir) {
+	var updated bool
+	d.path = normalizePath(d.path)
+
+	switch d.DirType() {
+	case Cache:
+		updated = a.cache != nil
+		a.cache = &d
+
+	case Config:
+		updated = a.config != nil
+		a.config = &d
+
+	case Home:
+		updated = a.home != nil
+		a.home = &d
+
+	case Temp:
+		updated = a.temp != nil
+		a.temp = &d
+		a.tempBase = "" // only trust a fallback base for the temp directory created by CreateTemp
 
 	case Workspace:
 		updated = a.workspace != nil
 		a.workspace = &d
+
+	case PersistentTemp:
+		updated = a.persistentTemp != nil
+		a.persistentTemp = &d
+
+	default:
+		if a.custom == nil {
+			a.custom = make(map[DirType]*Dir)
+		}
+		updated = a.custom[d.DirType()] != nil
+		a.custom[d.DirType()] = &d
+	}
+
+	// update the keywords maps
+	if updated {
+		a.initKeywords()
+	} else {
+		// initialize keyword maps if needed
+		if a.keywords == nil {
+			a.keywords = make(map[string]string)
+		}
+		if a.keywordsReverse == nil {
+			a.keywordsReverse = make(map[string]string)
+		}
+
+		for _, alias := range d.Aliases() {
+			a.keywords[alias] = d.Path()
+		}
+		if alias := d.CanonicalAlias(); alias != "" {
+			a.keywordsReverse[d.Path()] = alias // use the canonical alias for a reverse substitution
+		}
+		a.applyAliasPriority()
+	}
+}
+
+// Bind populates the string fields of the struct pointed to by v with the path of the directory specified by the
+// field's tag, e.g. `workspace:"cache"`. The tag value is the name of the directory type as returned by
+// DirType.String(). Fields without tag are ignored, and fields of directories that are not set are assigned an empty
+// string. An error is returned if v is not a pointer to a struct, or if a tagged field is not a string or refers to an
+// unknown directory type.
+func (a *AppDirs) Bind(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot bind directories, expected pointer to struct")
+	}
+
+	rv = rv.Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		field := rv.Type().Field(i)
+		name, found := field.Tag.Lookup("workspace")
+		if !found {
+			continue
+		}
+
+		dirType, found := parseDirType(name)
+		if !found {
+			return fmt.Errorf("cannot bind field '%s', unknown directory type: %s", field.Name, name)
+		}
+		if field.Type.Kind() != reflect.String || !rv.Field(i).CanSet() {
+			return fmt.Errorf("cannot bind field '%s', expected exported string", field.Name)
+		}
+
+		var path string
+		if d := a.dir(dirType); d != nil {
+			path = d.Path()
+		}
+		rv.Field(i).SetString(path)
+	}
+
+	return nil
+}
+
+// Both returns the absolute and parameterized representation of the input, e.g. for logging purposes. The absolute
+// path is computed once using MakeAbsolute, and is then parameterized similar to Parameterize.
+func (a *AppDirs) Both(basePath string, input string) (absolute string, parameterized string) {
+	absolute = a.MakeAbsolute(basePath, input)
+	return absolute, a.Parameterize(basePath, absolute)
+}
+
+// Cache retrieves the current cache directory. It returns an empty string if the directory is not set. Use Assign() to
+// initialize a new Cache directory.
+func (a *AppDirs) Cache() string {
+	if a.cache != nil {
+		return a.cache.Path()
+	}
+	return ""
+}
+
+// Collisions returns the directory types that share the same path, keyed by path. The types are listed in enumeration
+// order. Paths used by a single directory are omitted. For example, the Config and Workspace directories collide when
+// both are resolved to the workspace root (see WithRootConfig).
+func (a *AppDirs) Collisions() map[string][]DirType {
+	shared := make(map[string][]DirType)
+	for _, entry := range a.Entries() {
+		if entry.Path != "" {
+			shared[entry.Path] = append(shared[entry.Path], entry.Type)
+		}
+	}
+	for path, types := range shared {
+		if len(types) < 2 {
+			delete(shared, path)
+		}
+	}
+	return shared
+}
+
+// ComposePath returns a parameterized representation of the input suitable for docker-compose files, e.g.
+// "${CACHE}/sub/file". The leading keyword uses the braced form, so compose can interpolate it from the environment,
+// and the path uses forward slashes. A leading keyword that is not a valid variable name is replaced with its path.
+// Use ExportShell to define the matching environment variables.
+func (a *AppDirs) ComposePath(basePath string, input string) string {
+	path := a.Parameterize(basePath, input)
+
+	segments := strings.SplitN(path, string(os.PathSeparator), 2)
+	if d := a.dirByAlias(segments[0]); d != nil {
+		if name := aliasName(segments[0]); isVarName(name) {
+			segments[0] = "${" + name + "}"
+		} else {
+			segments[0] = d.Path()
+		}
+	}
+
+	return filepath.ToSlash(strings.Join(segments, string(os.PathSeparator)))
+}
+
+// Config retrieves the current config directory. It returns an empty string if the directory is not set. Use Assign()
+// to initialize a new Config directory.
+func (a *AppDirs) Config() string {
+	if a.config != nil {
+		return a.config.Path()
+	}
+	return ""
+}
+
+// ConfigCandidates returns the paths to check for a configuration file in priority order: the current working
+// directory, the workspace directory, the config directory, and the home directory. Directories that are not set are
+// skipped, and duplicate paths are only included once. Callers typically load the first candidate that exists.
+func (a *AppDirs) ConfigCandidates(filename string) []string {
+	var dirs []string
+	if wd, e := os.Getwd(); e == nil {
+		dirs = append(dirs, wd)
+	}
+	dirs = append(dirs, a.Workspace(), a.Config(), a.Home())
+
+	candidates := make([]string, 0, len(dirs))
+	for _, d := range dirs {
+		if d == "" {
+			continue
+		}
+		if c := filepath.Join(d, filename); !exists(candidates, c) {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
+
+// Create creates the directory of the given type, including any missing parents. The mode defaults to the default mode
+// of the directory type (see DirType.DefaultMode), use WithMode to specify an explicit mode. The effective mode is
+// subject to the umask of the process, use WithExactMode to enforce the mode instead. Nothing happens if the
+// directory already exists.
+func (a *AppDirs) Create(dirType DirType, opts ...CreateOption) error {
+	d := a.dir(dirType)
+	if d == nil || d.Path() == "" {
+		return fmt.Errorf("cannot create %s directory, invalid state", dirType.String())
+	}
+
+	_, exists := os.Stat(d.Path())
+	options := newCreateOptions(dirType, opts)
+	if e := os.MkdirAll(d.Path(), options.mode); e != nil {
+		return fmt.Errorf("cannot create %s directory: %s", dirType.String(), d.Path())
+	}
+
+	// bypass the umask if the exact mode is requested for a new directory
+	if exists != nil && options.exact {
+		if e := os.Chmod(d.Path(), options.mode); e != nil {
+			return fmt.Errorf("cannot set mode of %s directory: %s", dirType.String(), d.Path())
+		}
+	}
+
+	return nil
+}
+
+// CreateTemp creates the application's temp directory. The mode defaults to 0700 (see DirType.DefaultMode), use
+// WithMode to specify an explicit mode. Nothing happens if the directory already exists. The directory is created in
+// a fallback directory, such as '/dev/shm', if the parent directory is read-only, see WithTempFallback. Use Temp to
+// retrieve the chosen location and TempFallback to retrieve the chosen fallback directory.
+func (a *AppDirs) CreateTemp(opts ...CreateOption) (err error) {
+	// identify the temp dir path
+	path := a.Temp()
+	if path == "" {
+		// return an error when no temp dir is defined, probably a was not initialized using NewAppDirs
+		return fmt.Errorf("cannot create temp directory, invalid state")
+	}
+
+	// check if the path already exists, return an error if it's a file or invalid path
+	info, e := os.Stat(path)
+	if e == nil {
+		if info.IsDir() {
+			return nil
+		}
+		return fmt.Errorf("cannot create temp directory: '%s'", path)
+	}
+
+	// create the temp directory, switching to the first usable fallback directory if the parent directory is read-only
+	options := newCreateOptions(Temp, opts)
+	if e = os.MkdirAll(filepath.Dir(path), options.mode); e == nil {
+		e = mkdir(path, options.mode)
+	}
+	if e != nil && isReadOnly(e) {
+		for _, fallback := range append(append([]string{}, options.fallbacks...), tempFallbacks...) {
+			if fallback == "" || !filepath.IsAbs(fallback) {
+				continue
+			}
+			candidate := filepath.Join(fallback, filepath.Base(path))
+			if e = mkdir(candidate, options.mode); e == nil {
+				path = candidate
+				a.Assign(Dir{dirType: Temp, path: path, aliases: a.temp.Aliases()})
+				a.tempBase = filepath.Clean(fallback)
+				break
+			}
+		}
+	}
+	if e != nil {
+		return fmt.Errorf("cannot create temp directory: %s", path)
+	}
+
+	// bypass the umask if the exact mode is requested
+	if options.exact {
+		if e := os.Chmod(path, options.mode); e != nil {
+			return fmt.Errorf("cannot set mode of temp directory: %s", path)
+		}
+	}
+
+	return err
+}
+
+// Dedup returns the directory types that share their path with a directory of a lower type, in enumeration order. The
+// lowest type of each shared path is considered authoritative and is not reported. For example, Workspace is reported
+// when both Config and Workspace are resolved to the workspace root. Use PruneDuplicates to remove the aliases of the
+// reported directories from the keyword map.
+func (a *AppDirs) Dedup() []DirType {
+	var duplicates []DirType
+	for _, types := range a.Collisions() {
+		duplicates = append(duplicates, types[1:]...)
+	}
+	sort.Slice(duplicates, func(i, j int) bool { return duplicates[i] < duplicates[j] })
+	return duplicates
+}
+
+// Depth returns the number of path segments between the directory of the given type and path, e.g. 1 for a direct
+// child and 2 for a grandchild. It returns 0 if path equals the directory. An error is returned if the directory is
+// not set or if path is not within the directory.
+func (a *AppDirs) Depth(dirType DirType, path string) (int, error) {
+	d := a.dir(dirType)
+	if d == nil || d.Path() == "" {
+		return 0, fmt.Errorf("cannot compute depth, %s directory is not set", dirType.String())
+	}
+
+	rel, ok := relWithin(d.Path(), path)
+	if !ok {
+		return 0, fmt.Errorf("path is not within %s directory: %s", dirType.String(), path)
+	}
+	if rel == "." {
+		return 0, nil
+	}
+	return len(strings.Split(rel, string(os.PathSeparator))), nil
+}
+
+// DetectConfig scans the config directory for a configuration file with the provided base name and returns its path
+// and format. The extensions ".yaml", ".yml", ".json", and ".toml" are tried in order, returning the format "yaml",
+// "json", or "toml" respectively. An error wrapping ErrConfigNotFound is returned if none of the files exist.
+func (a *AppDirs) DetectConfig(baseName string) (path string, format string, err error) {
+	dir := a.Config()
+	if dir == "" {
+		return "", "", fmt.Errorf("cannot detect configuration file, invalid state")
+	}
+
+	for _, f := range configFormats {
+		path = filepath.Join(dir, baseName+f.ext)
+		if info, e := os.Stat(path); e == nil && info.Mode().IsRegular() {
+			return path, f.format, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("%w: %s", ErrConfigNotFound, filepath.Join(dir, baseName))
+}
+
+// Entries returns the configured directories in enumeration order, omitting directories that are not set. The entries
+// provide a stable, display-ready overview of the directories, their paths, and their aliases.
+func (a *AppDirs) Entries() []Entry {
+	types := registeredDirTypes()
+	entries := make([]Entry, 0, len(types))
+	for _, t := range types {
+		if d := a.dir(t); d != nil {
+			entries = append(entries, Entry{Type: t, Path: d.Path(), Aliases: d.Aliases()})
+		}
+	}
+	return entries
+}
+
+// Environ returns an environment variable for each keyword produced by ComposePath, formatted as "NAME=value", e.g.
+// "CACHE=/home/user/.cache/app". The result is sorted by variable name and can be appended to os.Environ() to pass the
+// directory layout to a child process using exec.Cmd.Env. Keywords that are not valid variable names are omitted.
+func (a *AppDirs) Environ() []string {
+	names, vars := a.exportVars()
+	env := make([]string, 0, len(names))
+	for _, name := range names {
+		env = append(env, name+"="+vars[name])
+	}
+	return env
+}
+
+// ExistingAncestor returns the deepest existing path for a given input, which may be the input itself. The input is
+// expanded and converted to an absolute path using MakeAbsolute first. An error is returned if no existing ancestor
+// can be found, or if the existence of a path cannot be verified.
+func (a *AppDirs) ExistingAncestor(basePath string, input string) (string, error) {
+	return existingAncestor(a.MakeAbsolute(basePath, input))
+}
+
+// ExpandConfined expands the leading keyword of the input and verifies the expanded path stays within the directory
+// associated with the keyword, e.g. "$CACHE/ok" is accepted whereas "$CACHE/../escape" is rejected. An error is
+// returned if the input does not start with a keyword, or if the path traverses above the keyword's directory.
+func (a *AppDirs) ExpandConfined(input string) (string, error) {
+	segments := strings.SplitN(input, string(os.PathSeparator), 2)
+	base, found := a.keywords[segments[0]]
+	if !found || base == "" {
+		return "", fmt.Errorf("cannot expand path without leading keyword: %s", input)
+	}
+
+	path := filepath.Clean(base)
+	if len(segments) > 1 {
+		path = filepath.Join(base, segments[1])
+	}
+	if _, ok := relWithin(base, path); !ok {
+		return "", fmt.Errorf("path escapes directory of keyword %s: %s", segments[0], input)
+	}
+
+	return path, nil
+}
+
+// ExpandReader reads the input from r line by line, replaces the keywords within each line, and writes the result to w.
+// The keywords are replaced similar to ExpandQuoted. Keywords spanning multiple lines are not supported. The line
+// endings of the input are preserved, which enables the streaming expansion of large files.
+func (a *AppDirs) ExpandReader(r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	for {
+		line, e := reader.ReadString('\n')
+		if len(line) > 0 {
+			if _, err := io.WriteString(w, a.expandText(line)); err != nil {
+				return err
+			}
+		}
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+	}
+}
+
+// ExpandQuoted replaces the keywords within an input string with their replacement values, including keywords within
+// single-quoted or double-quoted substrings. The quotes are preserved in the output, which enables the expansion of
+// embedded paths such as "$HOME/my docs". Keywords are only replaced when they represent a full token, delimited by
+// whitespace, quotes, or path separators.
+func (a *AppDirs) ExpandQuoted(input string) string {
+	return a.expandText(input)
+}
+
+// ExpandWithEnv replaces the keywords within an input string with their replacement values, similar to ExpandQuoted.
+// The remaining variables are expanded using os.Expand, consulting both the keywords and the environment variables.
+// This enables the combination of keywords such as $CACHE with environment variables such as $USER. Undefined
+// variables are replaced with an empty string, consistent with os.ExpandEnv, unless preserveUndefined is set. In that
+// case, undefined variables are kept as written, e.g. "$NAME" or "${NAME}".
+func (a *AppDirs) ExpandWithEnv(input string, preserveUndefined bool) string {
+	return expandVars(a.expandText(input), func(name string) (string, bool) {
+		if s, found := a.keywords["$"+name]; found {
+			return s, true
+		}
+		if s, found := os.LookupEnv(name); found {
+			return s, true
+		}
+		return "", !preserveUndefined
+	})
+}
+
+// ExportShell returns the shell statements defining an environment variable for each keyword produced by ComposePath,
+// e.g. "export CACHE='/home/user/.cache/app'". The statements are sorted by variable name and separated by newlines.
+// Keywords that are not valid variable names are omitted.
+func (a *AppDirs) ExportShell() string {
+	names, vars := a.exportVars()
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "export %s='%s'\n", name, strings.ReplaceAll(vars[name], "'", `'\''`))
+	}
+	return b.String()
+}
+
+// FindKeywords returns the keyword references within the input, classified as known or unknown. The forms "$NAME",
+// "${NAME}", and "%NAME%" are recognized, malformed references such as "$5", "${}", or "${NAME" are ignored. A
+// qualified reference, such as "$workspaceRoot:name", is considered known if the qualified keyword exists, otherwise
+// the reference without qualifier is classified instead. Each reference is listed once, in order of first occurrence.
+func (a *AppDirs) FindKeywords(input string) (known []string, unknown []string) {
+	seen := make(map[string]bool)
+	for _, match := range keywordPattern.FindAllStringSubmatch(input, -1) {
+		token := match[0]
+		if _, found := a.keywords[token]; !found && match[1] != "" {
+			token = strings.TrimSuffix(token, match[1])
+		}
+		if seen[token] {
+			continue
+		}
+		seen[token] = true
+
+		if _, found := a.keywords[token]; found {
+			known = append(known, token)
+		} else {
+			unknown = append(unknown, token)
+		}
+	}
+	return known, unknown
+}
+
+// FirstWritable returns the type and path of the first writable directory of the provided types. The types are
+// validated in order and each directory is created if it does not exist yet, using the default mode of the directory
+// type. Types that are not set are skipped. An error is returned if none of the directories is writable.
+func (a *AppDirs) FirstWritable(types ...DirType) (DirType, string, error) {
+	for _, t := range types {
+		d := a.dir(t)
+		if d == nil || d.Path() == "" {
+			continue
+		}
+
+		if e := a.Create(t); e != nil {
+			continue
+		}
+		if isWritable(d.Path()) {
+			return t, d.Path(), nil
+		}
+	}
+
+	return 0, "", fmt.Errorf("cannot find writable directory")
+}
+
+// GitignoreEntries returns the paths of the directories contained within the Workspace directory, suitable for a
+// .gitignore file. The paths are relative to the workspace, use forward slashes, and end with a trailing slash, e.g.
+// "cache/". The entries are sorted alphabetically and exclude the workspace itself. It returns an empty slice if the
+// Workspace directory is not set or no directory is contained within the workspace.
+func (a *AppDirs) GitignoreEntries() []string {
+	entries := make([]string, 0)
+	workspace := a.Workspace()
+	if workspace == "" {
+		return entries
 	}
 
-	// update the keywords maps
-	if updated {
-		a.initKeywords()
-	} else {
-		// initialize keyword maps if needed
-		if a.keywords == nil {
-			a.keywords = make(map[string]string)
+	seen := make(map[string]bool)
+	for _, entry := range a.Entries() {
+		if entry.Type == Workspace || entry.Path == "" {
+			continue
 		}
-		if a.keywordsReverse == nil {
-			a.keywordsReverse = make(map[string]string)
+		rel, ok := relWithin(workspace, entry.Path)
+		if !ok || rel == "." {
+			continue
 		}
-
-		for i, alias := range d.Aliases() {
-			a.keywords[alias] = d.Path()
-			if i == 0 {
-				a.keywordsReverse[d.Path()] = alias // use the first alias for a reverse substitution
-			}
+		if line := filepath.ToSlash(rel) + "/"; !seen[line] {
+			seen[line] = true
+			entries = append(entries, line)
 		}
 	}
+	sort.Strings(entries)
+	return entries
 }
 
-// Cache retrieves the current cache directory. It returns an empty string if the directory is not set. Use Assign() to
-// initialize a new Cache directory.
-func (a *AppDirs) Cache() string {
-	if a.cache != nil {
-		return a.cache.Path()
+// Home retrieves the current home directory. It returns an empty string if the directory is not set. Use Assign() to
+// initialize a new Home directory.
+func (a *AppDirs) Home() string {
+	if a.home != nil {
+		return a.home.Path()
 	}
 	return ""
 }
 
-// Config retrieves the current config directory. It returns an empty string if the directory is not set. Use Assign()
-// to initialize a new Config directory.
-func (a *AppDirs) Config() string {
-	if a.cache != nil {
-		return a.config.Path()
+// IsManaged returns true if the cleaned path equals or is within any of the configured directories, including the
+// named workspaces. Note that the home directory is considered a configured directory too, if set.
+func (a *AppDirs) IsManaged(path string) bool {
+	for _, t := range registeredDirTypes() {
+		if d := a.dir(t); d != nil && d.Path() != "" {
+			if _, ok := relWithin(d.Path(), path); ok {
+				return true
+			}
+		}
 	}
-	return ""
+	for _, d := range a.workspaces {
+		if _, ok := relWithin(d.Path(), path); ok {
+			return true
+		}
+	}
+	return false
 }
 
-// CreateTemp creates the application's temp directory, with mode set to 0755. Nothing happens if the directory
-// already exists.
-func (a *AppDirs) CreateTemp() (err error) {
-	// identify the temp dir path
-	path := a.Temp()
-	if path == "" {
-		// return an error when no temp dir is defined, probably a was not initialized using NewAppDirs
-		return fmt.Errorf("cannot create temp directory, invalid state")
+// JoinParam joins any number of path elements with the path of the directory of the given type and returns the
+// parameterized representation of the result, e.g. "$CACHE/sub/file". The joined absolute path is returned if the
+// directory has no aliases. An empty string is returned if the directory is not set.
+func (a *AppDirs) JoinParam(dirType DirType, elem ...string) string {
+	d := a.dir(dirType)
+	if d == nil {
+		return ""
 	}
+	return a.Parameterize("", filepath.Join(append([]string{d.Path()}, elem...)...))
+}
 
-	// check if the path already exists, return an error if it's a file or invalid path
-	info, e := os.Stat(path)
-	if e == nil {
-		if info.IsDir() {
-			return nil
+// Locate returns the type of the managed directory containing the path, together with the path relative to that
+// directory, e.g. Cache and "logs/app.log" for a log file within the cache directory. The deepest directory is used
+// when directories are nested, such as a temp directory within the workspace. Directories sharing the same path are
+// resolved in enumeration order, and named workspaces are reported as Workspace. The relative path is "." if the path
+// equals the directory. The result ok is false if the path is not within any of the managed directories.
+func (a *AppDirs) Locate(path string) (dirType DirType, rel string, ok bool) {
+	var deepest string
+	consider := func(t DirType, d *Dir) {
+		if d == nil || d.Path() == "" || len(d.Path()) <= len(deepest) {
+			return
+		}
+		if r, within := relWithin(d.Path(), path); within {
+			dirType, rel, ok, deepest = t, r, true, d.Path()
 		}
-		return fmt.Errorf("cannot create temp directory: '%s'", path)
 	}
 
-	// create the temp directory
-	if e := os.Mkdir(path, 0755); e != nil {
-		return fmt.Errorf("cannot create temp directory: %s", path)
+	for _, t := range registeredDirTypes() {
+		consider(t, a.dir(t))
+	}
+	names := make([]string, 0, len(a.workspaces))
+	for name := range a.workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		consider(Workspace, a.workspaces[name])
 	}
 
-	return err
+	return dirType, rel, ok
 }
 
-// Home retrieves the current home directory. It returns an empty string if the directory is not set. Use Assign() to
-// initialize a new Home directory.
-func (a *AppDirs) Home() string {
-	if a.home != nil {
-		return a.home.Path()
+// LockTemp acquires an exclusive lock on the application's temp directory, which prevents multiple instances from
+// using the same temp directory concurrently. The lock is represented by a lock file within the temp directory, which
+// is created if needed. An error is returned if the temp directory is locked already. Use the returned unlock function
+// to release the lock once done.
+func (a *AppDirs) LockTemp() (unlock func() error, err error) {
+	if e := a.CreateTemp(); e != nil {
+		return nil, e
 	}
-	return ""
+
+	path := filepath.Join(a.Temp(), tempLockFile)
+	f, e := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if e != nil {
+		if os.IsExist(e) {
+			return nil, fmt.Errorf("temp directory is locked: %s", a.Temp())
+		}
+		return nil, fmt.Errorf("cannot lock temp directory: %w", e)
+	}
+
+	// record the process id for diagnostic purposes
+	_, e = fmt.Fprintf(f, "%d\n", os.Getpid())
+	if ce := f.Close(); e == nil {
+		e = ce
+	}
+	if e != nil {
+		_ = os.Remove(path)
+		return nil, fmt.Errorf("cannot lock temp directory: %w", e)
+	}
+
+	return func() error {
+		if e := os.Remove(path); e != nil && !os.IsNotExist(e) {
+			return fmt.Errorf("cannot unlock temp directory: %w", e)
+		}
+		return nil
+	}, nil
 }
 
 // MakeAbsolute returns the absolute path for a given input. It replaces supported keywords with their replacement
-// values and converts a relative path to an absolute path. MakeAbsolute calls filepath.Clean on the result.
+// values and converts a relative path to an absolute path. Segments referring to the current directory (".") are
+// ignored when matching keywords. The special character "~" is only expanded as the first path component, e.g. "~/x",
+// and is kept as literal elsewhere, e.g. "a/~/b". On Windows, segments using the "%NAME%" syntax that do not match a
+// keyword are replaced with the value of the keyword "$NAME" or the environment variable NAME, if defined. Segments
+// using the shell syntax for fallbacks are supported too: "${NAME:-default}" is replaced with default if the keyword
+// "${NAME}" cannot be resolved, and "${NAME:+alt}" is replaced with alt only if it can, e.g. "${CACHE:-/tmp/cache}/x".
+// MakeAbsolute calls filepath.Clean on the result.
 func (a *AppDirs) MakeAbsolute(basePath string, input string) (path string) {
-	segments := strings.Split(input, string(os.PathSeparator))
-	var result string
+	path, _ = a.makeAbsolute(basePath, input, os.PathSeparator)
+	return path
+}
 
-	for i, segment := range segments {
-		s := a.keywords[segment]
-		if s != "" {
-			result = filepath.Join(result, s)
-		} else {
-			if runtime.GOOS == "windows" && i == 0 && strings.EqualFold(filepath.VolumeName(segment), segment) {
-				segment = fmt.Sprintf("%s%c", segment, filepath.Separator)
-			}
-			result = filepath.Join(result, segment)
-		}
+// MakeAbsoluteDir returns the absolute path for a given input similar to MakeAbsolute. In addition, it guarantees the
+// result ends with a path separator to signal the path is a directory, e.g. "/home/user/.cache/app/logs/". This is
+// useful for tools that distinguish between a directory and its contents, such as rsync.
+func (a *AppDirs) MakeAbsoluteDir(basePath string, input string) string {
+	path := a.MakeAbsolute(basePath, input)
+	if !strings.HasSuffix(path, string(os.PathSeparator)) {
+		path += string(os.PathSeparator)
 	}
+	return path
+}
 
-	// prepend the leading `/` if needed
-	if filepath.IsAbs(input) && runtime.GOOS != "windows" && !filepath.IsAbs(result) {
-		result = string(os.PathSeparator) + result
+// MakeAbsoluteSep returns the absolute path for a given input similar to MakeAbsolute, splitting the input on the
+// provided separator instead of the local path separator. This enables the expansion of keywords in paths originating
+// from another operating system, e.g. "$CACHE/logs" on Windows. The result uses the local path separator.
+func (a *AppDirs) MakeAbsoluteSep(basePath string, input string, sep rune) string {
+	path, _ := a.makeAbsolute(basePath, input, sep)
+	return path
+}
+
+// MakeAbsoluteStrict returns the absolute path for a given input similar to MakeAbsolute. In addition, it returns an
+// error wrapping ErrUnknownKeyword if a path segment is formatted as a keyword reference, but does not match any
+// keyword. A segment is considered a keyword reference if it uses braces (e.g. "${CACHE}"), or if it starts with a "$"
+// directly followed by a valid variable name consisting of letters, digits, and underscores (e.g. "$CACHE"). Other
+// segments containing a "$", such as "$5" or "$file.txt", are considered literal file names. Segments using the shell
+// syntax for fallbacks, such as "${CACHE:-/tmp/cache}", are accepted, as they resolve to their default.
+func (a *AppDirs) MakeAbsoluteStrict(basePath string, input string) (string, error) {
+	for _, segment := range splitSegments(input, os.PathSeparator) {
+		if _, found := a.keywords[segment]; found || !isKeywordRef(segment) || a.defaultKeywordPath(segment) != "" {
+			continue
+		}
+		if _, _, ok := a.expandFallback(segment); !ok {
+			return "", fmt.Errorf("%w: %s", ErrUnknownKeyword, segment)
+		}
 	}
 
-	return AbsPath(basePath, result)
+	return a.MakeAbsolute(basePath, input), nil
+}
+
+// MakeAbsoluteTrace returns the absolute path for a given input, similar to MakeAbsolute. In addition, it returns the
+// aliases that were substituted, in order of appearance. The returned slice is empty when no keywords are used.
+func (a *AppDirs) MakeAbsoluteTrace(basePath string, input string) (path string, used []string) {
+	return a.makeAbsolute(basePath, input, os.PathSeparator)
 }
 
 // MakeRelative returns the path for a given input relative to a base path. It replaces supported keywords with their
@@ -275,53 +1873,167 @@ func (a *AppDirs) MakeRelative(basePath string, input string) (path string) {
 	return filepath.Clean(input)
 }
 
+// MakeRelativeLiteral returns the path for a given input relative to a base path, similar to MakeRelative. Unlike
+// MakeRelative, it treats keywords literally and does not expand them. If input cannot be made relative to the base
+// path, the input itself is returned as result. MakeRelativeLiteral calls filepath.Clean on the result.
+func (a *AppDirs) MakeRelativeLiteral(basePath string, input string) (path string) {
+	abs := input
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(basePath, abs)
+	}
+
+	rel, e := filepath.Rel(basePath, abs)
+	if e == nil {
+		return rel
+	}
+	return filepath.Clean(input)
+}
+
+// Merge returns a new AppDirs combining the directories of a and override. Each directory that is set in override
+// replaces the directory of the same type in a, whereas directories not set in override fall back to a. Named
+// workspaces are merged by name similarly, and the alias priority of override is used if set. The directories are
+// copied and the keyword maps are rebuilt, neither a nor override is modified. A nil override returns a copy of a.
+func (a *AppDirs) Merge(override *AppDirs) *AppDirs {
+	merged := &AppDirs{}
+	for _, src := range []*AppDirs{a, override} {
+		if src == nil {
+			continue
+		}
+
+		for _, t := range registeredDirTypes() {
+			if d := src.dir(t); d != nil {
+				merged.Assign(Dir{dirType: d.dirType, path: d.path, aliases: d.Aliases()})
+				if t == Temp {
+					merged.tempBase = src.tempBase
+				}
+			}
+		}
+		for name, d := range src.workspaces {
+			if merged.workspaces == nil {
+				merged.workspaces = make(map[string]*Dir)
+			}
+			merged.workspaces[name] = &Dir{dirType: d.dirType, path: d.path, aliases: d.Aliases()}
+		}
+		if len(src.aliasPriority) > 0 {
+			merged.aliasPriority = append([]string(nil), src.aliasPriority...)
+		}
+		if src.fallbackApp != "" {
+			merged.fallbackApp = src.fallbackApp
+		}
+	}
+	merged.initKeywords()
+
+	return merged
+}
+
+// Overrides returns the current path of each configured directory that differs from its OS-default location, keyed by
+// directory type. The default location is determined by NewDir for the provided application name. A directory is
+// considered overridden when its default location cannot be determined.
+func (a *AppDirs) Overrides(appName string) map[DirType]string {
+	overrides := make(map[DirType]string)
+	for _, t := range registeredDirTypes() {
+		d := a.dir(t)
+		if d == nil {
+			continue
+		}
+		if def, e := NewDir(t, appName); e != nil || def.Path() != d.Path() {
+			overrides[t] = d.Path()
+		}
+	}
+	return overrides
+}
+
 // Parameterize returns the path for a given input relative to the provided base directory, if applicable. Matched path
-// segments are replaced with their parameter alias. A non-deterministic match is returned in case of duplicate
-// keywords. The first alias is returned when multiple aliases are defined for a directory. Parameterize calls
-// filepath.Clean on the result.
+// segments are replaced with their parameter alias. Only whole path segments match, so a sibling directory sharing
+// the same prefix is left untouched. A non-deterministic match is returned in case of duplicate keywords. The
+// canonical alias is returned when multiple aliases are defined for a directory, see Dir.CanonicalAlias. Parameterize
+// calls filepath.Clean on the result.
 func (a *AppDirs) Parameterize(basePath string, input string) (path string) {
-	// create an list of all key/value pairs, sorted by key length in descending order
-	type item struct {
-		key   string
-		value string
+	return a.parameterize(basePath, input, true)
+}
+
+// ParameterizeRaw returns the path for a given input with matched path segments replaced with their parameter alias,
+// similar to Parameterize. Unlike Parameterize, it does not call filepath.Clean on the result. A relative result within
+// basePath is still made relative to basePath, but any parent directory references are kept, e.g. "x/../y" for the
+// input "sub/x/../y" and basePath "sub". This preserves the structure of symbolic paths, such as "$CACHE/../shared",
+// which is useful for display purposes.
+func (a *AppDirs) ParameterizeRaw(basePath string, input string) (path string) {
+	return a.parameterize(basePath, input, false)
+}
+
+// PersistentTemp retrieves the current persistent temp directory. It returns an empty string if the directory is not
+// set. Use Assign() to initialize a new PersistentTemp directory.
+func (a *AppDirs) PersistentTemp() string {
+	if a.persistentTemp != nil {
+		return a.persistentTemp.Path()
 	}
-	ordered := make([]item, len(a.keywordsReverse))
-	for k, v := range a.keywordsReverse {
-		ordered = append(ordered, item{key: k, value: v})
+	return ""
+}
+
+// Rebase maps a path within the directory of type from to the equivalent location within the directory of type to,
+// preserving the relative sub path. An error is returned if either directory is not set or if path is not within the
+// directory of type from. Rebase calls filepath.Clean on the result.
+func (a *AppDirs) Rebase(path string, from DirType, to DirType) (string, error) {
+	src, dst := a.dir(from), a.dir(to)
+	if src == nil || dst == nil {
+		return "", fmt.Errorf("cannot rebase path, directory not set")
 	}
-	sort.SliceStable(ordered, func(i, j int) bool {
-		return len(ordered[i].key) > len(ordered[j].key)
-	})
 
-	// substitute the paths with their keyword
-	for _, o := range ordered {
-		input = strings.ReplaceAll(input, o.key, o.value)
+	rel, ok := relWithin(src.Path(), path)
+	if !ok {
+		return "", fmt.Errorf("path is not within %s directory: %s", from.String(), path)
 	}
 
-	// remove any trailing '/'
-	input = strings.TrimSuffix(input, string(os.PathSeparator))
+	return filepath.Join(dst.Path(), rel), nil
+}
 
-	if !filepath.IsAbs(input) {
-		result, err := filepath.Rel(basePath, input)
-		if err != nil {
-			return filepath.Clean(input)
-		}
-		return result
+// Portable returns an OS-independent, parameterized representation of the path for a given input. It parameterizes the
+// input using Parameterize, replaces a leading keyword with its bare "$" form (e.g. "$HOME" instead of "%USERPROFILE%"
+// or "${HOME}"), and uses forward slashes as separator. The result is identical for equivalent inputs on Unix, macOS,
+// and Windows.
+func (a *AppDirs) Portable(basePath string, input string) string {
+	path := a.Parameterize(basePath, input)
+
+	segments := strings.SplitN(path, string(os.PathSeparator), 2)
+	if d := a.dirByAlias(segments[0]); d != nil {
+		segments[0] = bareAlias(segments[0], d.Aliases())
+	}
+
+	return filepath.ToSlash(strings.Join(segments, string(os.PathSeparator)))
+}
+
+// PruneDuplicates removes the aliases of the directories reported by Dedup and returns their types. The directories
+// keep their path, but their keywords no longer resolve unless an authoritative directory of a lower type defines the
+// same keyword. The keyword maps are rebuilt, so the shared path is parameterized using the alias of the authoritative
+// directory.
+func (a *AppDirs) PruneDuplicates() []DirType {
+	duplicates := a.Dedup()
+	for _, t := range duplicates {
+		a.dir(t).aliases = []string{}
+	}
+	if len(duplicates) > 0 {
+		a.initKeywords()
 	}
-	return input
+	return duplicates
 }
 
 // RecreateTemp recreates a subdirectory of the application's temp directory, deleting all existing files. Leave
 // subdir empty to recreate the entire application's temp directory. It uses RemoveTempDir to safely remove the
-// directory. The mode is set to 0755.
+// directory. The mode is set to the default mode of the Temp directory type (0700). Concurrent calls for the same
+// subdir are serialized, as are concurrent calls for a subdir and the entire temp directory.
 func (a *AppDirs) RecreateTemp(subdir string) (err error) {
-	if e := a.RemoveTemp(subdir); e != nil {
+	defer tempLock(a.temp.Path(), subdir)()
+
+	if e := a.removeTemp(subdir, 1, 0); e != nil {
 		return e
 	}
 
-	// create the temp dir
+	// create the temp dir, including the parent directory of a namespaced application
 	path := filepath.Join(a.temp.Path(), subdir)
-	if e := os.Mkdir(path, 0755); e != nil {
+	if e := os.MkdirAll(filepath.Dir(path), Temp.DefaultMode()); e != nil {
+		return fmt.Errorf("cannot create temp directory: %s", path)
+	}
+	if e := os.Mkdir(path, Temp.DefaultMode()); e != nil {
 		return fmt.Errorf("cannot create temp directory: %s", path)
 	}
 
@@ -331,30 +2043,293 @@ func (a *AppDirs) RecreateTemp(subdir string) (err error) {
 // RemoveTemp removes the configured temp dir, deleting all existing files. It uses a failsafe to ensure the
 // configured temp dir is valid and within the scope of the system's default temp directory. The expected base paths
 // are '$TMPDIR' (on Unix or macOS) or '/tmp' (on Unix, macOS or Plan 9). On Windows, the directories can be either
-// '%TMP%' or '%TEMP%'.
+// '%TMP%' or '%TEMP%'. If the temp dir was moved to a fallback directory, the fallback directory is used as base path
+// instead. Symbolic links are evaluated prior to the validation, so a temp dir that resolves to a location outside of
+// the system's default temp directory is rejected. When removing the entire temp dir of a namespaced application, such
+// as '/tmp/Vendor/Product', the vendor directory is removed too if it's empty. Use RemoveTempRetry to retry the removal
+// on transient errors.
 func (a *AppDirs) RemoveTemp(subdir string) (err error) {
+	return a.RemoveTempRetry(subdir, 1, 0)
+}
 
-	// validate the configured temp directory is valid and safe
-	if a.temp.Path() == "" {
-		return fmt.Errorf("temp directory is not configured correctly")
+// RemoveTempRetry removes the configured temp dir similar to RemoveTemp, applying the same safety checks. The removal
+// is attempted up to attempts times as long as it fails with a transient error, such as an access denied or sharing
+// violation error caused by another process briefly holding a file on Windows. The delay between attempts starts at
+// delay and doubles after each failed attempt. Other errors are returned immediately.
+func (a *AppDirs) RemoveTempRetry(subdir string, attempts int, delay time.Duration) (err error) {
+	defer tempLock(a.temp.Path(), subdir)()
+
+	return a.removeTemp(subdir, attempts, delay)
+}
+
+// ReserveTempNamed creates a new, uniquely named subdirectory within the application's temp directory and returns its
+// path. The name is derived from the template, substituting the placeholders "{timestamp}" (UTC time formatted as
+// "20060102T150405"), "{rand}" (eight random hexadecimal characters), and "{pid}" (the current process ID), e.g.
+// "job-{timestamp}-{rand}". The placeholders are substituted again if the directory exists already. A numeric suffix
+// is appended to the name if the template yields the same name again, e.g. "job-2" for the template "job". The
+// expanded name should be a single path element, an error is returned otherwise. The temp directory is created if
+// needed, and the subdirectory uses the default mode of the Temp directory type (0700).
+func (a *AppDirs) ReserveTempNamed(template string) (path string, err error) {
+	if e := a.CreateTemp(); e != nil {
+		return "", e
 	}
-	tmp := filepath.Clean(os.TempDir())
-	current := filepath.Join(a.temp.Path(), subdir)
 
-	if !strings.HasPrefix(current, tmp) {
-		return fmt.Errorf("temp directory is considered unsafe")
+	var previous string
+	for i := 1; i <= maxReserveAttempts; i++ {
+		random := make([]byte, 4)
+		if _, e := rand.Read(random); e != nil {
+			return "", fmt.Errorf("cannot generate temp directory name: %w", e)
+		}
+		name := strings.NewReplacer(
+			"{timestamp}", time.Now().UTC().Format("20060102T150405"),
+			"{rand}", hex.EncodeToString(random),
+			"{pid}", strconv.Itoa(os.Getpid()),
+		).Replace(template)
+		if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+			return "", fmt.Errorf("cannot reserve temp directory, invalid name: %s", name)
+		}
+
+		// append a numeric suffix when the template yields the same name again
+		candidate := name
+		if name == previous {
+			candidate = fmt.Sprintf("%s-%d", name, i)
+		}
+		previous = name
+
+		path = filepath.Join(a.Temp(), candidate)
+		e := os.Mkdir(path, Temp.DefaultMode())
+		if e == nil {
+			return path, nil
+		}
+		if !os.IsExist(e) {
+			return "", fmt.Errorf("cannot reserve temp directory: %w", e)
+		}
 	}
 
-	if current == tmp {
+	return "", fmt.Errorf("cannot reserve temp directory, no unique name available: %s", template)
+}
+
+// ResolveMap returns a new map with the same keys as m, where each value is converted to an absolute path using
+// MakeAbsolute. Keywords are expanded and relative paths are resolved against basePath. Empty values are preserved.
+func (a *AppDirs) ResolveMap(basePath string, m map[string]string) map[string]string {
+	resolved := make(map[string]string, len(m))
+	for k, v := range m {
+		if v != "" {
+			v = a.MakeAbsolute(basePath, v)
+		}
+		resolved[k] = v
+	}
+	return resolved
+}
+
+// SamePhysical returns whether the directories of two types resolve to the same directory on disk, such as Cache and
+// Temp on minimal systems. Symbolic links are evaluated prior to the comparison. Both directories must exist.
+func (a *AppDirs) SamePhysical(first DirType, second DirType) (bool, error) {
+	paths := make([]string, 0, 2)
+	for _, dirType := range []DirType{first, second} {
+		d := a.dir(dirType)
+		if d == nil || d.Path() == "" {
+			return false, fmt.Errorf("cannot compare %s directory, invalid state", dirType.String())
+		}
+		path, e := filepath.EvalSymlinks(d.Path())
+		if e != nil {
+			return false, fmt.Errorf("cannot resolve %s directory: %w", dirType.String(), e)
+		}
+		paths = append(paths, path)
+	}
+
+	return paths[0] == paths[1], nil
+}
+
+// SetAliasPriority defines the order of precedence of aliases when multiple aliases refer to the same path, such as
+// "$workspaceRoot" and "$PWD". Parameterize and related functions prefer the alias that appears first in the order.
+// Aliases not included in the order, or not recognized, do not affect the substitution.
+func (a *AppDirs) SetAliasPriority(order []string) {
+	a.aliasPriority = make([]string, len(order))
+	copy(a.aliasPriority, order)
+	a.initKeywords()
+}
+
+// SetDefaultFallback enables the resolution of default keywords of unassigned directory types, such as "$CACHE" when
+// no Cache directory is assigned. MakeAbsolute and related functions then expand such a keyword to the default path of
+// its directory type for the given application name, as determined by NewDir. Without the fallback, the keyword is
+// treated as a literal path segment. An empty appName disables the fallback.
+func (a *AppDirs) SetDefaultFallback(appName string) {
+	a.fallbackApp = appName
+}
+
+// StalePIDFiles returns the files within the directory of the given type that end with suffix (e.g. ".pid") and
+// reference a process that no longer exists. Each file is expected to contain a process id. Files that cannot be
+// parsed are ignored, and subdirectories are not scanned. The existence of a process is verified on Unix and Windows,
+// on other platforms no files are considered stale.
+func (a *AppDirs) StalePIDFiles(dirType DirType, suffix string) ([]string, error) {
+	d := a.dir(dirType)
+	if d == nil {
+		return nil, fmt.Errorf("cannot scan %s directory, invalid state", dirType.String())
+	}
+
+	entries, e := os.ReadDir(d.Path())
+	if e != nil {
+		return nil, fmt.Errorf("cannot scan %s directory: %w", dirType.String(), e)
+	}
+
+	stale := make([]string, 0)
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !strings.HasSuffix(entry.Name(), suffix) {
+			continue
+		}
+
+		path := filepath.Join(d.Path(), entry.Name())
+		content, e := os.ReadFile(path)
+		if e != nil {
+			continue
+		}
+		pid, e := strconv.Atoi(strings.TrimSpace(string(content)))
+		if e != nil || pid <= 0 {
+			continue
+		}
+		if !processAlive(pid) {
+			stale = append(stale, path)
+		}
+	}
+
+	return stale, nil
+}
+
+// String converts a warning to it's string representation.
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s", w.DirType.String(), w.Message)
+}
+
+// StripKeyword identifies the keyword at the start of the input and returns it together with the remaining relative
+// portion of the input, e.g. "$CACHE" and "logs/app.log" for "$CACHE/logs/app.log". The input may reference the
+// keyword literally or contain the expanded path of a directory, in which case the canonical alias of the deepest
+// matching directory is returned. The remaining portion is cleaned and uses the platform-specific separator, it is
+// empty when the input refers to the directory itself. The result ok is false if the input has no leading keyword.
+func (a *AppDirs) StripKeyword(input string) (keyword string, rest string, ok bool) {
+	// identify a literal keyword as first path segment
+	segment := input
+	if i := strings.IndexFunc(input, func(r rune) bool { return r == '/' || r == os.PathSeparator }); i >= 0 {
+		segment, rest = input[:i], input[i+1:]
+	}
+	if _, found := a.keywords[segment]; !found {
+		// identify the deepest directory containing the expanded input
+		var deepest string
+		for path, alias := range a.keywordsReverse {
+			if r, within := relWithin(path, input); within && len(path) > len(deepest) {
+				deepest, segment, rest = path, alias, r
+			}
+		}
+		if deepest == "" {
+			return "", "", false
+		}
+	}
+
+	rest = filepath.Clean(filepath.FromSlash(rest))
+	if rest == "." {
+		rest = ""
+	}
+	return segment, rest, true
+}
+
+// SwapTemp replaces the target directory with the staging subdirectory of the application's temp directory. The
+// staging directory is renamed over the target, which achieves a near-atomic replacement. The previous target, if
+// any, is moved aside and removed once the staging directory is in place. The target must be an absolute path that
+// does not contain any of the configured directories, and both directories must reside on the same volume.
+func (a *AppDirs) SwapTemp(stagingSubdir string, targetDir string) error {
+	// validate the staging directory is a subdirectory of the temp directory
+	if a.temp == nil || a.temp.Path() == "" {
+		return fmt.Errorf("temp directory is not configured correctly")
+	}
+	staging := filepath.Join(a.temp.Path(), stagingSubdir)
+	if rel, ok := relWithin(a.temp.Path(), staging); !ok || rel == "." {
 		return fmt.Errorf("expected a subdirectory within the temp directory")
 	}
+	if info, e := os.Stat(staging); e != nil || !info.IsDir() {
+		return fmt.Errorf("cannot find staging directory: %s", staging)
+	}
 
-	// remove the temp dir if it exists
-	if e := os.RemoveAll(current); e != nil {
-		return e
+	// validate the target does not contain any of the configured directories
+	if !filepath.IsAbs(targetDir) {
+		return fmt.Errorf("%w: %s", ErrRelativePath, targetDir)
+	}
+	target := filepath.Clean(targetDir)
+	for _, entry := range a.Entries() {
+		if _, ok := relWithin(target, entry.Path); ok {
+			return fmt.Errorf("target directory is considered unsafe: %s", target)
+		}
 	}
 
-	return err
+	// validate the staging and target directory reside on the same volume
+	same, e := sameVolume(staging, filepath.Dir(target))
+	if e != nil {
+		return fmt.Errorf("cannot swap directory: %w", e)
+	}
+	if !same {
+		return fmt.Errorf("cannot swap directory across volumes: %s", target)
+	}
+
+	// move the existing target aside, rename the staging directory, and remove the previous target
+	var previous string
+	if _, e := os.Lstat(target); e == nil {
+		previous = fmt.Sprintf("%s.%d.old", target, os.Getpid())
+		if e := os.Rename(target, previous); e != nil {
+			return fmt.Errorf("cannot swap directory: %w", e)
+		}
+	}
+	if e := os.Rename(staging, target); e != nil {
+		if previous != "" {
+			_ = os.Rename(previous, target)
+		}
+		return fmt.Errorf("cannot swap directory: %w", e)
+	}
+	if previous != "" {
+		if e := os.RemoveAll(previous); e != nil {
+			return fmt.Errorf("cannot remove previous directory: %w", e)
+		}
+	}
+
+	return nil
+}
+
+// Symlink creates a symbolic link at fromRel within the directory of fromType, pointing to toRel within the directory
+// of toType. The link target is stored as a path relative to the link, so the link survives when the directories are
+// moved together. Missing parent directories of the link are created using the default mode of fromType. An error is
+// returned if either path is absolute or escapes its directory, or if the link cannot be created.
+func (a *AppDirs) Symlink(fromType DirType, fromRel string, toType DirType, toRel string) error {
+	link, e := a.managedPath(fromType, fromRel)
+	if e != nil {
+		return fmt.Errorf("cannot create symlink: %w", e)
+	}
+	target, e := a.managedPath(toType, toRel)
+	if e != nil {
+		return fmt.Errorf("cannot create symlink: %w", e)
+	}
+
+	rel, e := filepath.Rel(filepath.Dir(link), target)
+	if e != nil {
+		return fmt.Errorf("cannot create symlink: %w", e)
+	}
+	if e := os.MkdirAll(filepath.Dir(link), fromType.DefaultMode()); e != nil {
+		return fmt.Errorf("cannot create symlink: %w", e)
+	}
+	if e := os.Symlink(rel, link); e != nil {
+		return fmt.Errorf("cannot create symlink: %w", e)
+	}
+
+	return nil
+}
+
+// TakenAliases returns all aliases (keywords) currently associated with a directory, including the aliases of named
+// workspaces, sorted alphabetically. Callers can use the result to choose an alias that does not collide with an
+// existing one, e.g. before assigning a directory of a custom type.
+func (a *AppDirs) TakenAliases() []string {
+	aliases := make([]string, 0, len(a.keywords))
+	for alias := range a.keywords {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+	return aliases
 }
 
 // Temp retrieves the current temp directory. It returns an empty string if the directory is not set. Use Assign() to
@@ -366,6 +2341,12 @@ func (a *AppDirs) Temp() string {
 	return ""
 }
 
+// TempFallback retrieves the fallback directory chosen by CreateTemp when the parent of the temp directory is
+// read-only, such as '/dev/shm'. It returns an empty string if no fallback directory is used.
+func (a *AppDirs) TempFallback() string {
+	return a.tempBase
+}
+
 // Workspace retrieves the current workspace directory. It returns an empty string if the
 // directory is not set. Use Assign() to initialize a new Workspace directory.
 func (a *AppDirs) Workspace() string {
@@ -375,6 +2356,52 @@ func (a *AppDirs) Workspace() string {
 	return ""
 }
 
+// WorkspaceByName retrieves the path of a named workspace directory. It returns an empty string if no workspace with
+// the provided name is registered. Use AddWorkspace() to register a named workspace.
+func (a *AppDirs) WorkspaceByName(name string) string {
+	if d, found := a.workspaces[name]; found {
+		return d.Path()
+	}
+	return ""
+}
+
+// WriteTable writes an aligned table of the configured directories to w, listing the type, path, and canonical alias
+// of each directory (see Dir.CanonicalAlias). Directories without alias are shown with "-" as alias.
+func (a *AppDirs) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Type\tPath\tAlias")
+	for _, entry := range a.Entries() {
+		alias := a.dir(entry.Type).CanonicalAlias()
+		if alias == "" {
+			alias = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", entry.Type.String(), entry.Path, alias)
+	}
+	return tw.Flush()
+}
+
+// WithExactMode forces the mode of a newly created application directory, regardless of the umask of the process. The
+// directory is created first and its mode is then set explicitly. Only the target directory is updated, any missing
+// parents created by Create are subject to the umask.
+func WithExactMode() CreateOption {
+	return exactModeOption{}
+}
+
+// WithMode associates an explicit mode for creating an application directory. The default mode of the directory type
+// is used if omitted.
+func WithMode(mode os.FileMode) CreateOption {
+	return modeOption{Mode: mode}
+}
+
+// WithTempFallback defines a fallback directory for CreateTemp, which is used when the parent of the temp directory is
+// read-only, for example in immutable container environments. The fallback directories are tried in order, followed
+// by the default fallback '/dev/shm'. The temp directory is created as subdirectory of the first usable fallback,
+// using the name of the configured temp directory. Use Temp() to retrieve the chosen path. RemoveTemp does not trust
+// the fallback directory, so the temp directory can only be removed if it resides within the system's temp directory.
+func WithTempFallback(path string) CreateOption {
+	return tempFallbackOption{Path: path}
+}
+
 //======================================================================================================================
 // endregion
 //======================================================================================================================
@@ -8,10 +8,15 @@ package workspace
 //======================================================================================================================
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -32,6 +37,59 @@ const appName = "Test"
 // endregion
 //======================================================================================================================
 
+//======================================================================================================================
+// region Private Types
+//======================================================================================================================
+
+// stubResolver implements the Resolver interface using a single fixed directory for testing purposes.
+type stubResolver struct {
+	dir string
+}
+
+func (s stubResolver) Cache() string     { return s.dir }
+func (s stubResolver) Config() string    { return s.dir }
+func (s stubResolver) Home() string      { return s.dir }
+func (s stubResolver) Temp() string      { return s.dir }
+func (s stubResolver) Workspace() string { return s.dir }
+
+func (s stubResolver) MakeAbsolute(basePath string, input string) string {
+	return filepath.Join(s.dir, input)
+}
+
+func (s stubResolver) MakeRelative(basePath string, input string) string {
+	return input
+}
+
+func (s stubResolver) Parameterize(basePath string, input string) string {
+	return strings.Replace(input, s.dir, "$STUB", 1)
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Private Functions
+//======================================================================================================================
+
+// setEnv sets an environment variable for the duration of a test. The original value is restored during cleanup.
+func setEnv(t *testing.T, key string, value string) {
+	t.Helper()
+	original, ok := os.LookupEnv(key)
+	require.Nil(t, os.Setenv(key, value))
+	t.Cleanup(func() {
+		if ok {
+			_ = os.Setenv(key, original)
+		} else {
+			_ = os.Unsetenv(key)
+		}
+	})
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
 //======================================================================================================================
 // region Test Functions
 //======================================================================================================================
@@ -121,8 +179,20 @@ func TestConfig(t *testing.T) {
 	dirs, err := NewAppDirs(appName)
 	require.Nil(t, err, "Unexpected result when initializing app directories")
 
-	expectedConfig, _ := Root(appName)
-	assert.Equal(t, expectedConfig, dirs.Config())
+	base, e := os.UserConfigDir()
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(base, appName), dirs.Config())
+
+	// test the config and workspace directories are distinct by default
+	root, e := Root(appName)
+	require.Nil(t, e)
+	assert.Equal(t, root, dirs.Workspace())
+	assert.NotEqual(t, dirs.Workspace(), dirs.Config())
+
+	// test the config directory resolves to the workspace root when requested
+	d, e := NewDir(Config, appName, WithRootConfig())
+	require.Nil(t, e)
+	assert.Equal(t, root, d.Path())
 
 	dirs = &AppDirs{}
 	assert.Equal(t, "", dirs.Config())
@@ -196,6 +266,11 @@ func TestMakeAbsolute(t *testing.T) {
 		{input: filepath.Join("$PWD", "test"), expected: filepath.Join(dirs.Workspace(), "test")},
 		{input: filepath.Join("${PWD}", "test"), expected: filepath.Join(dirs.Workspace(), "test")},
 		{input: filepath.Join("$TEMPtest"), expected: filepath.Join(dirs.Workspace(), "$TEMPtest")},
+		{input: "." + string(os.PathSeparator) + "test", expected: filepath.Join(dirs.Workspace(), "test")},
+		{input: strings.Join([]string{"a", ".", "b"}, string(os.PathSeparator)), expected: filepath.Join(dirs.Workspace(), "a", "b")},
+		{input: strings.Join([]string{"$CACHE", ".", "x"}, string(os.PathSeparator)), expected: filepath.Join(dirs.Cache(), "x")},
+		{input: strings.Join([]string{".", "$CACHE", "x"}, string(os.PathSeparator)), expected: filepath.Join(dirs.Cache(), "x")},
+		{input: ".", expected: dirs.Workspace()},
 	}
 
 	if runtime.GOOS != "windows" {
@@ -345,6 +420,1311 @@ func TestMakeRelative(t *testing.T) {
 
 }
 
+func TestFirstWritable(t *testing.T) {
+	root := t.TempDir()
+
+	// block the cache directory by a regular file, which prevents the creation of the directory
+	blocked := filepath.Join(root, "blocked")
+	require.Nil(t, os.WriteFile(blocked, []byte{}, 0644))
+
+	dirs := &AppDirs{}
+	cache, e := NewDir(Cache, appName, WithPath(filepath.Join(blocked, "cache")))
+	require.Nil(t, e)
+	dirs.Assign(*cache)
+
+	temp, e := NewDir(Temp, appName, WithPath(filepath.Join(root, "temp")))
+	require.Nil(t, e)
+	dirs.Assign(*temp)
+
+	// test the unwritable cache and unset home directories are skipped
+	dirType, path, e := dirs.FirstWritable(Cache, Home, Temp)
+	require.Nil(t, e)
+	assert.Equal(t, Temp, dirType)
+	assert.Equal(t, filepath.Join(root, "temp"), path)
+	assert.DirExists(t, path)
+
+	// test no writable directory
+	_, _, e = dirs.FirstWritable(Cache, Home)
+	assert.EqualError(t, e, "cannot find writable directory")
+}
+
+func TestRebase(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	// test a cache file is rebased to the temp directory
+	got, e := dirs.Rebase(filepath.Join(dirs.Cache(), "sub", "file"), Cache, Temp)
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(dirs.Temp(), "sub", "file"), got)
+
+	// test the directory itself is rebased
+	got, e = dirs.Rebase(dirs.Cache(), Cache, Temp)
+	require.Nil(t, e)
+	assert.Equal(t, dirs.Temp(), got)
+
+	// test a path outside of the cache directory
+	outside := filepath.Join(dirs.Cache(), "..", "outside")
+	_, e = dirs.Rebase(outside, Cache, Temp)
+	assert.EqualError(t, e, fmt.Sprintf("path is not within cache directory: %s", outside))
+
+	// test an unset directory
+	_, e = (&AppDirs{}).Rebase(dirs.Cache(), Cache, Temp)
+	assert.EqualError(t, e, "cannot rebase path, directory not set")
+}
+
+func TestAssignTrailingSeparator(t *testing.T) {
+	root := t.TempDir()
+	dirs := &AppDirs{}
+	dirs.Assign(Dir{dirType: Cache, path: root + string(os.PathSeparator), aliases: defaultCache})
+
+	assert.Equal(t, root, dirs.Cache())
+	assert.Equal(t, filepath.Join(root, "test"), dirs.MakeAbsolute(root, filepath.Join("$CACHE", "test")))
+	assert.Equal(t, filepath.Join("$CACHE", "test"), dirs.Parameterize(root, filepath.Join(root, "test")))
+}
+
+func TestAddWorkspace(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	api := filepath.Join(dirs.Workspace(), "services", "api")
+	web := filepath.Join(dirs.Workspace(), "services", "web")
+	require.Nil(t, dirs.AddWorkspace("api", api))
+	require.Nil(t, dirs.AddWorkspace("web", web))
+
+	// test the named workspaces are registered
+	assert.Equal(t, api, dirs.WorkspaceByName("api"))
+	assert.Equal(t, web, dirs.WorkspaceByName("web"))
+	assert.Equal(t, "", dirs.WorkspaceByName("unknown"))
+
+	// test the named aliases are expanded and parameterized
+	assert.Equal(t, filepath.Join(api, "main.go"), dirs.MakeAbsolute(dirs.Workspace(), filepath.Join("$workspaceRoot:api", "main.go")))
+	assert.Equal(t, filepath.Join(web, "index.js"), dirs.MakeAbsolute(dirs.Workspace(), filepath.Join("${workspaceRoot:web}", "index.js")))
+	assert.Equal(t, filepath.Join("$workspaceRoot:api", "main.go"), dirs.Parameterize(dirs.Workspace(), filepath.Join(api, "main.go")))
+	assert.Equal(t, filepath.Join("$workspaceRoot:web", "index.js"), dirs.Parameterize(dirs.Workspace(), filepath.Join(web, "index.js")))
+
+	// test invalid workspaces
+	assert.EqualError(t, dirs.AddWorkspace("", api), "cannot add workspace without name")
+	assert.EqualError(t, dirs.AddWorkspace("api", "test"), "cannot process relative path: test")
+}
+
+func TestEntries(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	entries := dirs.Entries()
+	require.Len(t, entries, 6)
+
+	expected := []Entry{
+		{Type: Cache, Path: dirs.Cache(), Aliases: defaultCache},
+		{Type: Config, Path: dirs.Config(), Aliases: defaultConfig},
+		{Type: Home, Path: dirs.Home(), Aliases: defaultHome},
+		{Type: Workspace, Path: dirs.Workspace(), Aliases: defaultWorkspace},
+		{Type: Temp, Path: dirs.Temp(), Aliases: defaultTemp},
+		{Type: PersistentTemp, Path: dirs.PersistentTemp(), Aliases: defaultPersistentTemp},
+	}
+	assert.Equal(t, expected, entries)
+
+	// test unset directories are omitted
+	assert.Len(t, (&AppDirs{}).Entries(), 0)
+}
+
+func TestExpandQuoted(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	sep := string(os.PathSeparator)
+	type test struct {
+		input    string
+		expected string
+	}
+
+	var tests = []test{
+		{input: "$HOME" + sep + "my docs", expected: dirs.Home() + sep + "my docs"},
+		{input: `"$HOME` + sep + `my docs"`, expected: `"` + dirs.Home() + sep + `my docs"`},
+		{input: `'${CACHE}` + sep + `x' "$TEMP"`, expected: `'` + dirs.Cache() + sep + `x' "` + dirs.Temp() + `"`},
+		{input: `cp "$CACHE` + sep + `a b" $TEMP`, expected: `cp "` + dirs.Cache() + sep + `a b" ` + dirs.Temp()},
+		{input: `"$TEMPtest"`, expected: `"$TEMPtest"`},
+		{input: `"plain text"`, expected: `"plain text"`},
+	}
+
+	for _, curr := range tests {
+		assert.Equal(t, curr.expected, dirs.ExpandQuoted(curr.input))
+	}
+}
+
+func TestMakeRelativeLiteral(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	input := filepath.Join("$CACHE", "test")
+	expanded, e := filepath.Rel(dirs.Workspace(), filepath.Join(dirs.Cache(), "test"))
+	require.Nil(t, e)
+
+	// test the keyword is expanded by MakeRelative, but treated literally by MakeRelativeLiteral
+	assert.Equal(t, expanded, dirs.MakeRelative(dirs.Workspace(), input))
+	assert.Equal(t, input, dirs.MakeRelativeLiteral(dirs.Workspace(), input))
+	assert.Equal(t, "test", dirs.MakeRelativeLiteral(dirs.Workspace(), filepath.Join(dirs.Workspace(), "test")))
+}
+
+func TestExistingAncestor(t *testing.T) {
+	root := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(root, "a", "b"), 0755))
+
+	dirs := &AppDirs{}
+	d, e := NewDir(Cache, appName, WithPath(root))
+	require.Nil(t, e)
+	dirs.Assign(*d)
+
+	// test a partially existing path
+	got, e := dirs.ExistingAncestor(root, filepath.Join("$CACHE", "a", "b", "c", "d"))
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(root, "a", "b"), got)
+
+	// test a fully existing path
+	got, e = dirs.ExistingAncestor(root, filepath.Join("a", "b"))
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(root, "a", "b"), got)
+}
+
+func TestNewAppDirsVerbose(t *testing.T) {
+	// test no warnings are produced by default
+	dirs, warnings, e := NewAppDirsVerbose(appName)
+	require.Nil(t, e)
+	assert.Len(t, warnings, 0)
+	assert.Empty(t, dirs.Collisions())
+
+	// test the cache directory falls back to a sibling of the temp directory
+	original := userCacheDir
+	userCacheDir = func() (string, error) { return "", fmt.Errorf("cache directory not available") }
+	t.Cleanup(func() { userCacheDir = original })
+
+	dirs, warnings, e = NewAppDirsVerbose(appName)
+	require.Nil(t, e)
+	fallback := filepath.Join(os.TempDir(), appName+"-cache")
+	assert.Equal(t, fallback, dirs.Cache())
+	_, nested := relWithin(dirs.Temp(), dirs.Cache())
+	assert.False(t, nested)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, Cache, warnings[0].DirType)
+	assert.Equal(t, "cache: cannot resolve user cache directory, using fallback: "+fallback, warnings[0].String())
+}
+
+func TestIsManaged(t *testing.T) {
+	root := t.TempDir()
+	dirs := &AppDirs{}
+	for _, dirType := range []DirType{Cache, Temp} {
+		d, e := NewDir(dirType, appName, WithPath(filepath.Join(root, dirType.String())))
+		require.Nil(t, e)
+		dirs.Assign(*d)
+	}
+
+	assert.True(t, dirs.IsManaged(filepath.Join(dirs.Cache(), "test")))
+	assert.True(t, dirs.IsManaged(dirs.Cache()))
+	assert.True(t, dirs.IsManaged(filepath.Join(dirs.Temp(), "..", "temp", "test")))
+	assert.False(t, dirs.IsManaged(filepath.Join(root, "unrelated", "test")))
+	assert.False(t, dirs.IsManaged(root))
+	assert.False(t, (&AppDirs{}).IsManaged(dirs.Cache()))
+}
+
+func TestExpandWithEnv(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+	setEnv(t, "GO_WORKSPACE_USER", "user")
+
+	sep := string(os.PathSeparator)
+	type test struct {
+		input    string
+		preserve bool
+		expected string
+	}
+
+	var tests = []test{
+		{input: "$CACHE" + sep + "x", expected: dirs.Cache() + sep + "x"},
+		{input: "${TEMP}" + sep + "$GO_WORKSPACE_USER", expected: dirs.Temp() + sep + "user"},
+		{input: "$CACHE-${GO_WORKSPACE_USER}", expected: dirs.Cache() + "-user"},
+		{input: "$CACHE" + sep + "$GO_WORKSPACE_UNDEFINED" + sep + "x", expected: dirs.Cache() + sep + sep + "x"},
+		{input: "$CACHE" + sep + "$GO_WORKSPACE_UNDEFINED" + sep + "x", preserve: true, expected: dirs.Cache() + sep + "$GO_WORKSPACE_UNDEFINED" + sep + "x"},
+		{input: "${GO_WORKSPACE_UNDEFINED}" + sep + "x", preserve: true, expected: "${GO_WORKSPACE_UNDEFINED}" + sep + "x"},
+		{input: "$GO_WORKSPACE_UNDEFINED-$GO_WORKSPACE_USER", preserve: true, expected: "$GO_WORKSPACE_UNDEFINED-user"},
+		{input: "price $5 or $ and ${}", preserve: true, expected: "price $5 or $ and "},
+	}
+
+	for _, curr := range tests {
+		assert.Equal(t, curr.expected, dirs.ExpandWithEnv(curr.input, curr.preserve))
+	}
+}
+
+func TestPortable(t *testing.T) {
+	root := t.TempDir()
+	dirs := &AppDirs{}
+	for _, dirType := range []DirType{Cache, Home, Workspace} {
+		d, e := NewDir(dirType, appName, WithPath(filepath.Join(root, dirType.String())))
+		require.Nil(t, e)
+		dirs.Assign(*d)
+	}
+
+	type test struct {
+		input    string
+		expected string
+	}
+
+	// the expected output is identical for all operating systems
+	var tests = []test{
+		{input: filepath.Join(dirs.Cache(), "sub", "file"), expected: "$CACHE/sub/file"},
+		{input: filepath.Join(dirs.Home(), "docs"), expected: "$HOME/docs"},
+		{input: filepath.Join(dirs.Workspace(), "src", "main.go"), expected: "$workspaceRoot/src/main.go"},
+		{input: filepath.Join("sub", "file"), expected: "sub/file"},
+	}
+
+	for _, curr := range tests {
+		assert.Equal(t, curr.expected, dirs.Portable(dirs.Workspace(), curr.input))
+	}
+}
+
+func TestCreate(t *testing.T) {
+	root := t.TempDir()
+	dirs := &AppDirs{}
+
+	// test invalid state
+	assert.EqualError(t, dirs.Create(Cache), "cannot create cache directory, invalid state")
+
+	// test the directory and its parents are created
+	d, e := NewDir(Cache, appName, WithPath(filepath.Join(root, "a", "cache")))
+	require.Nil(t, e)
+	dirs.Assign(*d)
+	require.Nil(t, dirs.Create(Cache))
+	assert.DirExists(t, dirs.Cache())
+
+	// test an existing directory
+	require.Nil(t, dirs.Create(Cache, WithMode(0700)))
+}
+
+func TestMakeAbsoluteTrace(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	// test an input using two keywords
+	path, used := dirs.MakeAbsoluteTrace(dirs.Workspace(), filepath.Join("$TEMP", "${CACHE}", "test"))
+	assert.Equal(t, filepath.Join(dirs.Temp(), dirs.Cache(), "test"), path)
+	assert.Equal(t, []string{"$TEMP", "${CACHE}"}, used)
+
+	// test an input without keywords
+	path, used = dirs.MakeAbsoluteTrace(dirs.Workspace(), "test")
+	assert.Equal(t, filepath.Join(dirs.Workspace(), "test"), path)
+	assert.Empty(t, used)
+}
+
+func TestExpandReader(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	sep := string(os.PathSeparator)
+	input := "cache: $CACHE" + sep + "data\n" +
+		"# keep $TEMPtest literal\n" +
+		"temp: \"${TEMP}\"\r\n" +
+		"home: $HOME"
+	expected := "cache: " + dirs.Cache() + sep + "data\n" +
+		"# keep $TEMPtest literal\n" +
+		"temp: \"" + dirs.Temp() + "\"\r\n" +
+		"home: " + dirs.Home()
+
+	var b bytes.Buffer
+	require.Nil(t, dirs.ExpandReader(strings.NewReader(input), &b))
+	assert.Equal(t, expected, b.String())
+}
+
+func TestOverrides(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	// test no directories are overridden by default
+	assert.Len(t, dirs.Overrides(appName), 0)
+
+	// test a custom cache directory is reported
+	custom := filepath.Join(t.TempDir(), "cache")
+	d, e := NewDir(Cache, appName, WithPath(custom))
+	require.Nil(t, e)
+	dirs.Assign(*d)
+	assert.Equal(t, map[DirType]string{Cache: custom}, dirs.Overrides(appName))
+}
+
+func TestJoinParam(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	assert.Equal(t, filepath.Join("$CACHE", "sub", "file"), dirs.JoinParam(Cache, "sub", "file"))
+	assert.Equal(t, "$CACHE", dirs.JoinParam(Cache))
+	assert.Equal(t, "", (&AppDirs{}).JoinParam(Cache, "sub"))
+}
+
+func TestConfigCandidates(t *testing.T) {
+	root := t.TempDir()
+	dirs := &AppDirs{}
+	for _, dirType := range []DirType{Config, Home, Workspace} {
+		d, e := NewDir(dirType, appName, WithPath(filepath.Join(root, dirType.String())))
+		require.Nil(t, e)
+		dirs.Assign(*d)
+	}
+	wd, e := os.Getwd()
+	require.Nil(t, e)
+
+	// test the candidates are returned in priority order
+	expected := []string{
+		filepath.Join(wd, "app.yml"),
+		filepath.Join(root, "workspace", "app.yml"),
+		filepath.Join(root, "config", "app.yml"),
+		filepath.Join(root, "home", "app.yml"),
+	}
+	assert.Equal(t, expected, dirs.ConfigCandidates("app.yml"))
+
+	// test duplicate and unset directories are skipped
+	dirs = &AppDirs{}
+	d, e := NewDir(Workspace, appName, WithPath(wd))
+	require.Nil(t, e)
+	dirs.Assign(*d)
+	assert.Equal(t, []string{filepath.Join(wd, "app.yml")}, dirs.ConfigCandidates("app.yml"))
+}
+
+func TestLockTemp(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+
+	// test the lock is acquired
+	unlock, e := dirs.LockTemp()
+	require.Nil(t, e)
+	assert.FileExists(t, filepath.Join(dirs.Temp(), ".lock"))
+
+	// test the lock cannot be acquired twice
+	_, e = dirs.LockTemp()
+	assert.EqualError(t, e, fmt.Sprintf("temp directory is locked: %s", dirs.Temp()))
+
+	// test the lock is acquired again after releasing it
+	require.Nil(t, unlock())
+	unlock, e = dirs.LockTemp()
+	require.Nil(t, e)
+	require.Nil(t, unlock())
+}
+
+func TestParameterizeRaw(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	sep := string(os.PathSeparator)
+	input := dirs.Cache() + sep + ".." + sep + "shared"
+
+	// test the raw output preserves the parent directory reference
+	assert.Equal(t, "$CACHE"+sep+".."+sep+"shared", dirs.ParameterizeRaw(dirs.Workspace(), input))
+	assert.Equal(t, "shared", dirs.Parameterize(dirs.Workspace(), input))
+	assert.Equal(t, filepath.Join("$CACHE", "test"), dirs.ParameterizeRaw(dirs.Workspace(), filepath.Join(dirs.Cache(), "test")))
+
+	// test a relative input is made relative to basePath without cleaning
+	input = "sub" + sep + "x" + sep + ".." + sep + "y"
+	assert.Equal(t, "x"+sep+".."+sep+"y", dirs.ParameterizeRaw("sub", input))
+	assert.Equal(t, "y", dirs.Parameterize("sub", input))
+	assert.Equal(t, input, dirs.ParameterizeRaw("other", input))
+}
+
+func TestDetectConfig(t *testing.T) {
+	type test struct {
+		file   string
+		format string
+	}
+
+	var tests = []test{
+		{file: "app.yaml", format: "yaml"},
+		{file: "app.yml", format: "yaml"},
+		{file: "app.json", format: "json"},
+		{file: "app.toml", format: "toml"},
+	}
+
+	for _, curr := range tests {
+		root := t.TempDir()
+		dirs := &AppDirs{}
+		d, e := NewDir(Config, appName, WithPath(root))
+		require.Nil(t, e)
+		dirs.Assign(*d)
+		require.Nil(t, os.WriteFile(filepath.Join(root, curr.file), []byte{}, 0644))
+
+		path, format, e := dirs.DetectConfig("app")
+		require.Nil(t, e)
+		assert.Equal(t, filepath.Join(root, curr.file), path)
+		assert.Equal(t, curr.format, format)
+
+		// test a missing configuration file
+		_, _, e = dirs.DetectConfig("missing")
+		assert.True(t, errors.Is(e, ErrConfigNotFound))
+	}
+}
+
+func TestNewAppDirsWithoutRoot(t *testing.T) {
+	wd, e := os.Getwd()
+	require.Nil(t, e)
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	// test the config and workspace directories fall back to the working directory outside a repository
+	dir := t.TempDir()
+	require.Nil(t, os.Chdir(dir))
+	expected, e := os.Getwd()
+	require.Nil(t, e)
+
+	dirs, warnings, e := NewAppDirsVerbose(appName)
+	require.Nil(t, e)
+	assert.Equal(t, expected, dirs.Workspace())
+	require.Len(t, warnings, 1)
+	assert.Equal(t, Workspace, warnings[0].DirType)
+
+	// test the config directory falls back to the working directory when the user config directory is unavailable
+	original := userConfigDir
+	userConfigDir = func() (string, error) { return "", fmt.Errorf("config directory not available") }
+	t.Cleanup(func() { userConfigDir = original })
+
+	dirs, warnings, e = NewAppDirsVerbose(appName)
+	require.Nil(t, e)
+	assert.Equal(t, expected, dirs.Config())
+	require.Len(t, warnings, 4)
+	assert.Equal(t, Config, warnings[0].DirType)
+	assert.Equal(t, "config: cannot resolve user config directory, using fallback: "+expected, warnings[1].String())
+	assert.Equal(t, Workspace, warnings[2].DirType)
+	assert.Equal(t, "config: directory shares its path with workspace: "+expected, warnings[3].String())
+}
+
+func TestResolveMap(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	base := dirs.Workspace()
+
+	input := map[string]string{
+		"cache":    filepath.Join("$CACHE", "data"),
+		"temp":     filepath.Join("${TEMP}", "run"),
+		"relative": filepath.Join("sub", "file"),
+		"absolute": dirs.Home(),
+		"empty":    "",
+	}
+	expected := map[string]string{
+		"cache":    filepath.Join(dirs.Cache(), "data"),
+		"temp":     filepath.Join(dirs.Temp(), "run"),
+		"relative": filepath.Join(base, "sub", "file"),
+		"absolute": dirs.Home(),
+		"empty":    "",
+	}
+
+	// test the values are resolved and the input is left untouched
+	assert.Equal(t, expected, dirs.ResolveMap(base, input))
+	assert.Equal(t, filepath.Join("$CACHE", "data"), input["cache"])
+}
+
+func TestAcquireScratch(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+
+	// test a new scratch directory is created within the temp directory
+	path, release, e := dirs.AcquireScratch()
+	require.Nil(t, e)
+	assert.Equal(t, dirs.Temp(), filepath.Dir(path))
+	require.Nil(t, os.WriteFile(filepath.Join(path, "file"), []byte("test"), 0600))
+	require.Nil(t, os.Mkdir(filepath.Join(path, "sub"), 0700))
+
+	// test a concurrently acquired scratch directory is different
+	other, releaseOther, e := dirs.AcquireScratch()
+	require.Nil(t, e)
+	assert.NotEqual(t, path, other)
+	releaseOther()
+
+	// test the released scratch directory is emptied and reused
+	release()
+	release()
+	reused, releaseReused, e := dirs.AcquireScratch()
+	require.Nil(t, e)
+	defer releaseReused()
+	assert.Equal(t, path, reused)
+	entries, e := os.ReadDir(reused)
+	require.Nil(t, e)
+	assert.Len(t, entries, 0)
+
+	// test the number of idle scratch directories is bounded
+	releases := make([]func(), 0)
+	for i := 0; i < maxScratchDirs+2; i++ {
+		_, r, e := dirs.AcquireScratch()
+		require.Nil(t, e)
+		releases = append(releases, r)
+	}
+	for _, r := range releases {
+		r()
+	}
+	matches, e := filepath.Glob(filepath.Join(dirs.Temp(), scratchPattern))
+	require.Nil(t, e)
+	assert.Len(t, matches, maxScratchDirs+1)
+}
+
+func TestSamePhysical(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	require.Nil(t, os.MkdirAll(dirs.Cache(), 0700))
+	require.Nil(t, dirs.CreateTemp())
+
+	// test distinct directories
+	same, e := dirs.SamePhysical(Cache, Temp)
+	require.Nil(t, e)
+	assert.False(t, same)
+
+	// test identical directories
+	d, e := NewDir(Temp, appName, WithPath(dirs.Cache()))
+	require.Nil(t, e)
+	dirs.Assign(*d)
+	same, e = dirs.SamePhysical(Cache, Temp)
+	require.Nil(t, e)
+	assert.True(t, same)
+
+	// test a directory that does not exist
+	d, e = NewDir(Temp, appName, WithPath(filepath.Join(t.TempDir(), "missing")))
+	require.Nil(t, e)
+	dirs.Assign(*d)
+	_, e = dirs.SamePhysical(Cache, Temp)
+	assert.NotNil(t, e)
+}
+
+func TestComposePath(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	base := dirs.Workspace()
+
+	// test the braced output
+	assert.Equal(t, "${CACHE}/sub/file", dirs.ComposePath(base, filepath.Join(dirs.Cache(), "sub", "file")))
+	assert.Equal(t, "${TEMP}", dirs.ComposePath(base, dirs.Temp()))
+
+	// test a keyword that is not a valid variable name is replaced with its path
+	other := filepath.Join(t.TempDir(), "other")
+	require.Nil(t, dirs.AddWorkspace("other", other))
+	assert.Equal(t, filepath.ToSlash(filepath.Join(other, "file")),
+		dirs.ComposePath(base, filepath.Join(other, "file")))
+
+	// test the corresponding environment variables
+	export := dirs.ExportShell()
+	assert.Contains(t, export, fmt.Sprintf("export CACHE='%s'\n", dirs.Cache()))
+	assert.Contains(t, export, fmt.Sprintf("export TEMP='%s'\n", dirs.Temp()))
+	assert.NotContains(t, export, "workspaceRoot:other")
+}
+
+func TestNewAppDirsEmptyName(t *testing.T) {
+	for _, name := range []string{"", "   "} {
+		dirs, e := NewAppDirs(name)
+		assert.Nil(t, dirs)
+		assert.True(t, errors.Is(e, ErrInvalidAppName), name)
+	}
+}
+
+func TestSwapTemp(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	require.Nil(t, dirs.CreateTemp())
+
+	// build the staging content and an existing target
+	staging := filepath.Join(dirs.Temp(), "staging")
+	require.Nil(t, os.Mkdir(staging, 0700))
+	require.Nil(t, os.WriteFile(filepath.Join(staging, "new"), []byte("new"), 0600))
+	target := filepath.Join(t.TempDir(), "published")
+	require.Nil(t, os.Mkdir(target, 0755))
+	require.Nil(t, os.WriteFile(filepath.Join(target, "old"), []byte("old"), 0600))
+
+	// test the staging directory replaces the target
+	require.Nil(t, dirs.SwapTemp("staging", target))
+	assert.NoDirExists(t, staging)
+	assert.FileExists(t, filepath.Join(target, "new"))
+	assert.NoFileExists(t, filepath.Join(target, "old"))
+	matches, e := filepath.Glob(target + ".*")
+	require.Nil(t, e)
+	assert.Len(t, matches, 0)
+
+	// test invalid staging directories and targets
+	assert.NotNil(t, dirs.SwapTemp("missing", target))
+	assert.NotNil(t, dirs.SwapTemp("", target))
+	require.Nil(t, os.Mkdir(staging, 0700))
+	assert.True(t, errors.Is(dirs.SwapTemp("staging", "relative"), ErrRelativePath))
+	e = dirs.SwapTemp("staging", filepath.Dir(dirs.Cache()))
+	assert.EqualError(t, e, "target directory is considered unsafe: "+filepath.Dir(dirs.Cache()))
+}
+
+func TestSetAliasPriority(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	base := dirs.Home()
+	input := filepath.Join(dirs.Workspace(), "file")
+
+	// test the priority forces an alias of the same directory
+	dirs.SetAliasPriority([]string{"$PWD"})
+	assert.Equal(t, filepath.Join("$PWD", "file"), dirs.Parameterize(base, input))
+	dirs.SetAliasPriority([]string{"$workspaceRoot", "$PWD"})
+	assert.Equal(t, filepath.Join("$workspaceRoot", "file"), dirs.Parameterize(base, input))
+
+	// test the priority applies to overlapping directories
+	d, e := NewDir(Cache, appName, WithPath(dirs.Workspace()))
+	require.Nil(t, e)
+	dirs.Assign(*d)
+	dirs.SetAliasPriority([]string{"$CACHE", "$workspaceRoot"})
+	assert.Equal(t, filepath.Join("$CACHE", "file"), dirs.Parameterize(base, input))
+	dirs.SetAliasPriority([]string{"$PWD", "$CACHE"})
+	assert.Equal(t, filepath.Join("$PWD", "file"), dirs.Parameterize(base, input))
+}
+
+func TestMakeAbsoluteTilde(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the home directory is not associated with '~' on Windows")
+	}
+	dirs := NewTestAppDirs(t, appName)
+	base := dirs.Workspace()
+
+	// test '~' is only expanded as first path component
+	assert.Equal(t, filepath.Join(dirs.Home(), "x"), dirs.MakeAbsolute(base, filepath.Join("~", "x")))
+	assert.Equal(t, filepath.Join(base, "a", "~", "b"), dirs.MakeAbsolute(base, filepath.Join("a", "~", "b")))
+	assert.Equal(t, filepath.Join(dirs.Cache(), "~backup"), dirs.MakeAbsolute(base, filepath.Join("$CACHE", "~backup")))
+	assert.Equal(t, dirs.Home(), dirs.MakeAbsolute(base, "~"))
+}
+
+func TestMakeAbsoluteStrict(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	base := dirs.Workspace()
+
+	// test a valid keyword
+	path, e := dirs.MakeAbsoluteStrict(base, filepath.Join("$CACHE", "file"))
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(dirs.Cache(), "file"), path)
+
+	// test typo keywords
+	for _, input := range []string{filepath.Join("$CACH", "file"), filepath.Join("sub", "${CACH}")} {
+		_, e = dirs.MakeAbsoluteStrict(base, input)
+		assert.True(t, errors.Is(e, ErrUnknownKeyword), input)
+	}
+	_, e = dirs.MakeAbsoluteStrict(base, filepath.Join("$CACH", "file"))
+	assert.EqualError(t, e, "unknown keyword: $CACH")
+
+	// test legitimate literals with a dollar sign
+	for _, input := range []string{"$5", "price$", "$file.txt", "a$b", "$"} {
+		path, e = dirs.MakeAbsoluteStrict(base, filepath.Join("sub", input))
+		require.Nil(t, e, input)
+		assert.Equal(t, filepath.Join(base, "sub", input), path)
+	}
+
+	// test fallbacks are accepted, both when the default is skipped and when it is applied
+	fallback := filepath.Join(os.TempDir(), "fallback")
+	path, e = dirs.MakeAbsoluteStrict(base, filepath.Join("${CACHE:-"+fallback+"}", "file"))
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(dirs.Cache(), "file"), path)
+	path, e = dirs.MakeAbsoluteStrict(base, filepath.Join("${DATA:-"+fallback+"}", "file"))
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(fallback, "file"), path)
+	path, e = dirs.MakeAbsoluteStrict(base, filepath.Join("${DATA:+alt}", "file"))
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(base, "file"), path)
+}
+
+func TestBoth(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+
+	// test a keyworded relative input
+	absolute, parameterized := dirs.Both(dirs.Workspace(), filepath.Join("${CACHE}", "sub", "..", "file"))
+	assert.Equal(t, filepath.Join(dirs.Cache(), "file"), absolute)
+	assert.Equal(t, filepath.Join("$CACHE", "file"), parameterized)
+
+	// test a plain relative input
+	absolute, parameterized = dirs.Both(dirs.Cache(), "file")
+	assert.Equal(t, filepath.Join(dirs.Cache(), "file"), absolute)
+	assert.Equal(t, filepath.Join("$CACHE", "file"), parameterized)
+}
+
+func TestResolver(t *testing.T) {
+	// configFile resolves the path of a configuration file, illustrating a consumer depending on the interface
+	configFile := func(r Resolver, name string) string {
+		return r.MakeAbsolute(r.Config(), name)
+	}
+
+	// test a stub implementation
+	stub := stubResolver{dir: t.TempDir()}
+	assert.Equal(t, filepath.Join(stub.dir, "app.yml"), configFile(stub, "app.yml"))
+	assert.Equal(t, filepath.Join("$STUB", "app.yml"), stub.Parameterize("", filepath.Join(stub.dir, "app.yml")))
+
+	// test the application directories
+	dirs := NewTestAppDirs(t, appName)
+	assert.Equal(t, filepath.Join(dirs.Config(), "app.yml"), configFile(dirs, "app.yml"))
+}
+
+func TestTempFallback(t *testing.T) {
+	original, originalFallbacks := mkdir, tempFallbacks
+	t.Cleanup(func() { mkdir, tempFallbacks = original, originalFallbacks })
+
+	// simulate a read-only system temp directory
+	readOnly := filepath.Clean(os.TempDir())
+	mkdir = func(path string, mode os.FileMode) error {
+		if filepath.Dir(path) == readOnly {
+			return &os.PathError{Op: "mkdir", Path: path, Err: os.ErrPermission}
+		}
+		return original(path, mode)
+	}
+	fallback := t.TempDir()
+	tempFallbacks = []string{filepath.Join(t.TempDir(), "missing"), fallback}
+
+	// test a new instance does not probe the temp directory
+	dirs, warnings, e := NewAppDirsVerbose(appName)
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(readOnly, appName), dirs.Temp())
+	for _, w := range warnings {
+		assert.NotEqual(t, Temp, w.DirType, w.String())
+	}
+
+	// test the temp directory falls back to a writable mount when created
+	require.Nil(t, dirs.CreateTemp())
+	assert.Equal(t, filepath.Join(fallback, appName), dirs.Temp())
+	assert.Equal(t, fallback, dirs.TempFallback())
+	require.Nil(t, dirs.RemoveTemp(""))
+	assert.NoDirExists(t, dirs.Temp())
+
+	// test the temp directory falls back to a user-specified path when created
+	dirs = NewTestAppDirs(t, appName)
+	readOnly = filepath.Dir(dirs.Temp())
+	custom := t.TempDir()
+	require.Nil(t, dirs.CreateTemp(WithTempFallback(custom)))
+	assert.Equal(t, filepath.Join(custom, "temp"), dirs.Temp())
+	assert.Equal(t, custom, dirs.TempFallback())
+	assert.DirExists(t, dirs.Temp())
+	assert.Equal(t, filepath.Join(custom, "temp", "x"), dirs.MakeAbsolute(dirs.Workspace(), filepath.Join("$TEMP", "x")))
+	require.Nil(t, dirs.RemoveTemp(""))
+	assert.NoDirExists(t, dirs.Temp())
+}
+
+func TestBind(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+
+	// test the tagged fields are filled
+	var settings struct {
+		Name   string
+		Cache  string `workspace:"cache"`
+		Config string `workspace:"config"`
+		Home   string `workspace:"home"`
+	}
+	settings.Name = "unchanged"
+	require.Nil(t, dirs.Bind(&settings))
+	assert.Equal(t, "unchanged", settings.Name)
+	assert.Equal(t, dirs.Cache(), settings.Cache)
+	assert.Equal(t, dirs.Config(), settings.Config)
+	assert.Equal(t, dirs.Home(), settings.Home)
+
+	// test invalid targets and fields
+	assert.EqualError(t, dirs.Bind(settings), "cannot bind directories, expected pointer to struct")
+	var unknown struct {
+		Data string `workspace:"unknown"`
+	}
+	assert.EqualError(t, dirs.Bind(&unknown), "cannot bind field 'Data', unknown directory type: unknown")
+	var invalid struct {
+		Temp int `workspace:"temp"`
+	}
+	assert.EqualError(t, dirs.Bind(&invalid), "cannot bind field 'Temp', expected exported string")
+}
+
+func TestMakeAbsoluteDir(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	sep := string(os.PathSeparator)
+
+	// test the trailing separator is present
+	assert.Equal(t, filepath.Join(dirs.Cache(), "logs")+sep, dirs.MakeAbsoluteDir(dirs.Workspace(), filepath.Join("$CACHE", "logs")+sep))
+	assert.Equal(t, filepath.Join(dirs.Cache(), "logs")+sep, dirs.MakeAbsoluteDir(dirs.Workspace(), filepath.Join("$CACHE", "logs")))
+	assert.Equal(t, dirs.Cache()+sep, dirs.MakeAbsoluteDir(dirs.Workspace(), "$CACHE"))
+
+	// test a root path is not appended with an additional separator
+	root := filepath.VolumeName(dirs.Cache()) + sep
+	assert.Equal(t, root, dirs.MakeAbsoluteDir(dirs.Workspace(), root))
+}
+
+func TestWriteTable(t *testing.T) {
+	dirs := &AppDirs{}
+	for _, d := range []Dir{
+		{dirType: Cache, path: filepath.FromSlash("/cache"), aliases: []string{"$CACHE"}},
+		{dirType: Config, path: filepath.FromSlash("/workspace/config")},
+		{dirType: Temp, path: filepath.FromSlash("/tmp/app"), aliases: []string{"$TEMP", "$TMP"}},
+	} {
+		dirs.Assign(d)
+	}
+
+	var buf bytes.Buffer
+	require.Nil(t, dirs.WriteTable(&buf))
+	expected := "" +
+		"Type    Path               Alias\n" +
+		"cache   /cache             $CACHE\n" +
+		"config  /workspace/config  -\n" +
+		"temp    /tmp/app           $TEMP\n"
+	assert.Equal(t, filepath.FromSlash(expected), buf.String())
+}
+
+func TestNewAppDirsNamespaced(t *testing.T) {
+	base, e := os.UserCacheDir()
+	require.Nil(t, e)
+
+	// test the nested structure of the cache and temp directories
+	dirs, e := NewAppDirsNamespaced("Vendor", "Product")
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(base, "Vendor", "Product"), dirs.Cache())
+	assert.Equal(t, filepath.Join(os.TempDir(), "Vendor", "Product"), dirs.Temp())
+	assert.Equal(t, "Vendor", filepath.Base(filepath.Dir(dirs.PersistentTemp())))
+
+	// test invalid vendor and product names
+	for _, names := range [][]string{{"", "Product"}, {"Vendor", ""}, {"Vendor/Sub", "Product"}, {"Vendor", ".."}} {
+		_, e = NewAppDirsNamespaced(names[0], names[1])
+		assert.True(t, errors.Is(e, ErrInvalidAppName), names)
+	}
+}
+
+func TestExpandConfined(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+
+	// test a path within the keyword's directory
+	path, e := dirs.ExpandConfined(filepath.Join("$CACHE", "ok"))
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(dirs.Cache(), "ok"), path)
+	sep := string(os.PathSeparator)
+	path, e = dirs.ExpandConfined("${CACHE}" + sep + "sub" + sep + ".." + sep + "ok")
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(dirs.Cache(), "ok"), path)
+	path, e = dirs.ExpandConfined("$CACHE")
+	require.Nil(t, e)
+	assert.Equal(t, dirs.Cache(), path)
+
+	// test a path escaping the keyword's directory
+	input := "$CACHE" + sep + ".." + sep + "escape"
+	_, e = dirs.ExpandConfined(input)
+	assert.EqualError(t, e, "path escapes directory of keyword $CACHE: "+input)
+
+	// test inputs without a leading keyword
+	for _, input := range []string{filepath.Join("sub", "$CACHE"), dirs.Cache(), "$UNKNOWN"} {
+		_, e = dirs.ExpandConfined(input)
+		assert.EqualError(t, e, "cannot expand path without leading keyword: "+input)
+	}
+}
+
+func TestMakeAbsoluteSep(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	base := dirs.Workspace()
+
+	// test a path using forward slashes
+	assert.Equal(t, filepath.Join(dirs.Cache(), "sub", "file"), dirs.MakeAbsoluteSep(base, "$CACHE/sub/file", '/'))
+	assert.Equal(t, filepath.Join(base, "sub", "file"), dirs.MakeAbsoluteSep(base, "./sub/file", '/'))
+
+	// test a path using backslashes
+	assert.Equal(t, filepath.Join(dirs.Temp(), "file"), dirs.MakeAbsoluteSep(base, `${TEMP}\file`, '\\'))
+}
+
+func TestAliasPrefix(t *testing.T) {
+	d, e := NewDir(Cache, appName, WithPath(t.TempDir()), WithAliasPrefix("plugin."))
+	require.Nil(t, e)
+	assert.Equal(t, []string{"$plugin.CACHE", "${plugin.CACHE}"}, d.Aliases())
+
+	// test the prefixed aliases are recognized instead of the default aliases
+	dirs := &AppDirs{}
+	dirs.Assign(*d)
+	base := t.TempDir()
+	assert.Equal(t, filepath.Join(d.Path(), "x"), dirs.MakeAbsolute(base, filepath.Join("$plugin.CACHE", "x")))
+	assert.Equal(t, filepath.Join(d.Path(), "x"), dirs.MakeAbsolute(base, filepath.Join("${plugin.CACHE}", "x")))
+	assert.Equal(t, filepath.Join("$plugin.CACHE", "x"), dirs.Parameterize(base, filepath.Join(d.Path(), "x")))
+	assert.Equal(t, filepath.Join(base, "$CACHE", "x"), dirs.MakeAbsolute(base, filepath.Join("$CACHE", "x")))
+
+	// test aliases without sigil are omitted
+	d, e = NewDir(Home, appName, WithAliases([]string{"$HOME", "~"}), WithAliasPrefix("plugin."))
+	require.Nil(t, e)
+	assert.Equal(t, []string{"$plugin.HOME"}, d.Aliases())
+}
+
+func TestDepth(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+
+	type test struct {
+		Path     string
+		Expected int
+	}
+
+	tests := []test{
+		{Path: dirs.Cache(), Expected: 0},
+		{Path: filepath.Join(dirs.Cache(), "child"), Expected: 1},
+		{Path: filepath.Join(dirs.Cache(), "child", "grandchild"), Expected: 2},
+		{Path: filepath.Join(dirs.Cache(), "child", "..", "other") + string(os.PathSeparator), Expected: 1},
+	}
+	for _, test := range tests {
+		depth, e := dirs.Depth(Cache, test.Path)
+		require.Nil(t, e, test.Path)
+		assert.Equal(t, test.Expected, depth, test.Path)
+	}
+
+	// test an outside path
+	outside := filepath.Join(dirs.Temp(), "file")
+	_, e := dirs.Depth(Cache, outside)
+	assert.EqualError(t, e, "path is not within cache directory: "+outside)
+
+	// test a directory that is not set
+	_, e = (&AppDirs{}).Depth(Cache, outside)
+	assert.EqualError(t, e, "cannot compute depth, cache directory is not set")
+}
+
+func TestKeywordRoundTrip(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	sep := string(os.PathSeparator)
+
+	for _, base := range []string{dirs.Workspace(), dirs.Cache(), filepath.Dir(dirs.Cache()), ""} {
+		// test exact keywords expand to the directory itself
+		assert.Equal(t, dirs.Cache(), dirs.MakeAbsolute(base, "$CACHE"), base)
+		assert.Equal(t, dirs.Cache(), dirs.MakeAbsolute(base, "${CACHE}"), base)
+		assert.Equal(t, dirs.Cache(), dirs.MakeAbsolute(base, "$CACHE"+sep), base)
+
+		// test the directory itself parameterizes to the exact keyword
+		assert.Equal(t, "$CACHE", dirs.Parameterize(base, dirs.Cache()), base)
+		assert.Equal(t, "$CACHE", dirs.Parameterize(base, dirs.Cache()+sep), base)
+		assert.Equal(t, "$CACHE", dirs.ParameterizeRaw(base, dirs.Cache()), base)
+		assert.Equal(t, dirs.Cache(), dirs.MakeAbsolute(base, dirs.Parameterize(base, dirs.Cache())), base)
+	}
+
+	// test siblings sharing the directory as prefix are not parameterized
+	sibling := dirs.Cache() + "-old"
+	assert.Equal(t, filepath.Join(sibling, "x"), dirs.ParameterizeRaw("", filepath.Join(sibling, "x")))
+	nested := filepath.Join(t.TempDir(), "nested") + dirs.Cache()
+	assert.Equal(t, nested, dirs.ParameterizeRaw("", nested))
+}
+
+func TestEnviron(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+
+	// test each directory is exported using its canonical variable name
+	env := dirs.Environ()
+	assert.True(t, sort.StringsAreSorted(env))
+	assert.Contains(t, env, "CACHE="+dirs.Cache())
+	assert.Contains(t, env, "HOME="+dirs.Home())
+	assert.Contains(t, env, "PERSISTENT_TEMP="+dirs.PersistentTemp())
+	assert.Contains(t, env, "TEMP="+dirs.Temp())
+
+	// test the entries match the shell statements
+	export := dirs.ExportShell()
+	for _, entry := range env {
+		i := strings.Index(entry, "=")
+		assert.Contains(t, export, fmt.Sprintf("export %s='%s'\n", entry[:i], entry[i+1:]))
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := NewTestAppDirs(t, appName)
+	require.Nil(t, base.AddWorkspace("docs", filepath.Join(t.TempDir(), "docs")))
+
+	// override the cache directory only
+	cache, e := NewDir(Cache, appName, WithPath(filepath.Join(t.TempDir(), "cache")))
+	require.Nil(t, e)
+	override := &AppDirs{}
+	override.Assign(*cache)
+
+	// test the override takes precedence and the other directories fall back to the base
+	merged := base.Merge(override)
+	assert.Equal(t, cache.Path(), merged.Cache())
+	assert.Equal(t, base.Config(), merged.Config())
+	assert.Equal(t, base.Home(), merged.Home())
+	assert.Equal(t, base.Temp(), merged.Temp())
+	assert.Equal(t, base.Workspace(), merged.Workspace())
+	assert.Equal(t, base.PersistentTemp(), merged.PersistentTemp())
+
+	// test the keywords are rebuilt
+	assert.Equal(t, filepath.Join(cache.Path(), "file"), merged.MakeAbsolute("", filepath.Join("$CACHE", "file")))
+	assert.Equal(t, filepath.Join("$CACHE", "file"), merged.ParameterizeRaw("", filepath.Join(cache.Path(), "file")))
+	assert.Equal(t, filepath.Join("$TEMP", "file"), merged.ParameterizeRaw("", filepath.Join(base.Temp(), "file")))
+	assert.Equal(t, base.MakeAbsolute("", "$workspaceRoot:docs"), merged.MakeAbsolute("", "$workspaceRoot:docs"))
+
+	// test the inputs are not modified
+	assert.NotEqual(t, cache.Path(), base.Cache())
+	assert.Equal(t, "", override.Temp())
+
+	// test a nil override returns a copy
+	assert.Equal(t, base.Entries(), base.Merge(nil).Entries())
+}
+
+func TestAbsolutize(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	base := filepath.Join(t.TempDir(), "project")
+	cache := filepath.Join("$CACHE", "sub", "file")
+
+	// test a keyworded value round-trips through MakeRelative and Absolutize
+	rel := dirs.MakeRelative(base, cache)
+	assert.False(t, filepath.IsAbs(rel))
+	assert.Equal(t, cache, dirs.Absolutize(base, rel))
+
+	// test keywords within the input are expanded
+	assert.Equal(t, cache, dirs.Absolutize(base, cache))
+
+	// test a path outside of the managed directories remains absolute
+	assert.Equal(t, filepath.Join(base, "local"), dirs.Absolutize(base, "local"))
+	assert.Equal(t, filepath.Join(base, "local"), dirs.Absolutize(base, dirs.MakeRelative(base, filepath.Join(base, "local"))))
+}
+
+func TestNewAppDirsFromEnv(t *testing.T) {
+	defaults, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	// test the variables override a subset of the directories
+	cache := filepath.Join(t.TempDir(), "cache")
+	temp := filepath.Join(t.TempDir(), "temp")
+	setEnv(t, "MYAPP_CACHE", cache)
+	setEnv(t, "MYAPP_TEMP", temp)
+	setEnv(t, "MYAPP_CONFIG", "")
+	dirs, e := NewAppDirsFromEnv(appName, "MYAPP")
+	require.Nil(t, e)
+	assert.Equal(t, cache, dirs.Cache())
+	assert.Equal(t, temp, dirs.Temp())
+	assert.Equal(t, defaults.Config(), dirs.Config())
+	assert.Equal(t, defaults.Home(), dirs.Home())
+	assert.Equal(t, defaults.Workspace(), dirs.Workspace())
+	assert.Equal(t, filepath.Join(cache, "file"), dirs.MakeAbsolute("", filepath.Join("$CACHE", "file")))
+
+	// test a relative path is rejected
+	setEnv(t, "MYAPP_HOME", "relative")
+	_, e = NewAppDirsFromEnv(appName, "MYAPP")
+	assert.True(t, errors.Is(e, ErrRelativePath))
+}
+
+func TestStripKeyword(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	rest := filepath.Join("logs", "app.log")
+
+	// test a literal keyword
+	keyword, r, ok := dirs.StripKeyword("$CACHE/logs/app.log")
+	assert.True(t, ok)
+	assert.Equal(t, "$CACHE", keyword)
+	assert.Equal(t, rest, r)
+
+	keyword, r, ok = dirs.StripKeyword("${TEMP}")
+	assert.True(t, ok)
+	assert.Equal(t, "${TEMP}", keyword)
+	assert.Equal(t, "", r)
+
+	// test an expanded keyword
+	keyword, r, ok = dirs.StripKeyword(filepath.Join(dirs.Cache(), rest))
+	assert.True(t, ok)
+	assert.Equal(t, "$CACHE", keyword)
+	assert.Equal(t, rest, r)
+
+	// test an input without keyword
+	_, _, ok = dirs.StripKeyword(rest)
+	assert.False(t, ok)
+	_, _, ok = dirs.StripKeyword("$UNKNOWN/file")
+	assert.False(t, ok)
+	_, _, ok = dirs.StripKeyword(filepath.Join(t.TempDir(), "file"))
+	assert.False(t, ok)
+}
+
+func TestCollisions(t *testing.T) {
+	root := t.TempDir()
+	dirs := &AppDirs{}
+	for _, dirType := range []DirType{Cache, Temp} {
+		d, e := NewDir(dirType, appName, WithPath(filepath.Join(root, dirType.String())))
+		require.Nil(t, e)
+		dirs.Assign(*d)
+	}
+
+	// test directories with distinct paths do not collide
+	assert.Empty(t, dirs.Collisions())
+
+	// test a shared path is reported in enumeration order
+	d, e := NewDir(Temp, appName, WithPath(dirs.Cache()))
+	require.Nil(t, e)
+	dirs.Assign(*d)
+	assert.Equal(t, map[string][]DirType{dirs.Cache(): {Cache, Temp}}, dirs.Collisions())
+}
+
+func TestFindKeywords(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	require.Nil(t, dirs.AddWorkspace("docs", t.TempDir()))
+
+	input := `cache: "$CACHE/data" temp: ${TEMP}/run, log: ${LOGS}/app.log, again: $CACHE, docs: $workspaceRoot:docs, ` +
+		`other: $workspaceRoot:other, win: %APPDATA%, price: $5, empty: ${}, open: ${CACHE, percent: 50%`
+	known, unknown := dirs.FindKeywords(input)
+	assert.Equal(t, []string{"$CACHE", "${TEMP}", "$workspaceRoot:docs", "$workspaceRoot"}, known)
+	assert.Equal(t, []string{"${LOGS}", "%APPDATA%"}, unknown)
+
+	// test an input without keywords
+	known, unknown = dirs.FindKeywords("plain/path")
+	assert.Empty(t, known)
+	assert.Empty(t, unknown)
+}
+
+func TestReserveTempNamed(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+
+	// test the placeholders are substituted
+	pattern := regexp.MustCompile(fmt.Sprintf(`^job-\d{8}T\d{6}-[0-9a-f]{8}-%d$`, os.Getpid()))
+	seen := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		path, e := dirs.ReserveTempNamed("job-{timestamp}-{rand}-{pid}")
+		require.Nil(t, e)
+		assert.DirExists(t, path)
+		assert.Equal(t, dirs.Temp(), filepath.Dir(path))
+		assert.Regexp(t, pattern, filepath.Base(path))
+		assert.False(t, seen[path])
+		seen[path] = true
+	}
+
+	// test a numeric suffix ensures uniqueness of a fixed name
+	first, e := dirs.ReserveTempNamed("fixed")
+	require.Nil(t, e)
+	second, e := dirs.ReserveTempNamed("fixed")
+	require.Nil(t, e)
+	assert.Equal(t, "fixed", filepath.Base(first))
+	assert.Equal(t, "fixed-2", filepath.Base(second))
+
+	// test names escaping the temp directory are rejected
+	for _, template := range []string{"", "..", "sub/{rand}", `sub\{rand}`} {
+		_, e := dirs.ReserveTempNamed(template)
+		assert.NotNil(t, e, template)
+	}
+}
+
+func TestLocate(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	rel := filepath.Join("logs", "app.log")
+
+	// test paths within the cache and temp directories
+	dirType, r, ok := dirs.Locate(filepath.Join(dirs.Cache(), rel))
+	assert.True(t, ok)
+	assert.Equal(t, Cache, dirType)
+	assert.Equal(t, rel, r)
+
+	dirType, r, ok = dirs.Locate(dirs.Temp())
+	assert.True(t, ok)
+	assert.Equal(t, Temp, dirType)
+	assert.Equal(t, ".", r)
+
+	// test the deepest directory is used for nested directories
+	nested, e := NewDir(Temp, appName, WithPath(filepath.Join(dirs.Cache(), "tmp")))
+	require.Nil(t, e)
+	dirs.Assign(*nested)
+	dirType, r, ok = dirs.Locate(filepath.Join(nested.Path(), "file"))
+	assert.True(t, ok)
+	assert.Equal(t, Temp, dirType)
+	assert.Equal(t, "file", r)
+
+	// test an unmanaged path
+	_, _, ok = dirs.Locate(filepath.Join(t.TempDir(), "file"))
+	assert.False(t, ok)
+}
+
+func TestTakenAliases(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	taken := dirs.TakenAliases()
+	assert.True(t, sort.StringsAreSorted(taken))
+
+	// test the default aliases are reported
+	for _, dirType := range []DirType{Cache, Home, Temp, Workspace, PersistentTemp} {
+		for _, alias := range DefaultAliases(dirType) {
+			assert.Contains(t, taken, alias)
+		}
+	}
+
+	// test the aliases of a named workspace are reported
+	require.Nil(t, dirs.AddWorkspace("docs", t.TempDir()))
+	assert.Contains(t, dirs.TakenAliases(), "$workspaceRoot:docs")
+	assert.Empty(t, (&AppDirs{}).TakenAliases())
+}
+
+func TestDefaultFallback(t *testing.T) {
+	temp, e := NewDir(Temp, appName, WithPath(t.TempDir()))
+	require.Nil(t, e)
+	dirs := &AppDirs{}
+	dirs.Assign(*temp)
+	input := filepath.Join("$CACHE", "x")
+
+	// test an unassigned keyword is treated literally by default
+	base := t.TempDir()
+	assert.Equal(t, filepath.Join(base, "$CACHE", "x"), dirs.MakeAbsolute(base, input))
+	_, e = dirs.MakeAbsoluteStrict(base, input)
+	assert.True(t, errors.Is(e, ErrUnknownKeyword))
+
+	// test an unassigned keyword falls back to the default path of its type
+	cache, e := NewDir(Cache, appName)
+	require.Nil(t, e)
+	dirs.SetDefaultFallback(appName)
+	assert.Equal(t, filepath.Join(cache.Path(), "x"), dirs.MakeAbsolute(base, input))
+	strict, e := dirs.MakeAbsoluteStrict(base, input)
+	require.Nil(t, e)
+	assert.Equal(t, filepath.Join(cache.Path(), "x"), strict)
+
+	// test assigned and unknown keywords are unaffected
+	assert.Equal(t, filepath.Join(temp.Path(), "x"), dirs.MakeAbsolute(base, filepath.Join("$TEMP", "x")))
+	assert.Equal(t, filepath.Join(base, "$UNKNOWN"), dirs.MakeAbsolute(base, "$UNKNOWN"))
+
+	// test the fallback can be disabled
+	dirs.SetDefaultFallback("")
+	assert.Equal(t, filepath.Join(base, "$CACHE", "x"), dirs.MakeAbsolute(base, input))
+}
+
+func TestMakeAbsoluteFallback(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	base := dirs.Workspace()
+	fallback := filepath.Join(os.TempDir(), "fallback")
+
+	type test struct {
+		input    string
+		expected string
+	}
+
+	var tests = []test{
+		// test the default is skipped when the keyword resolves
+		{input: filepath.Join("${CACHE:-"+fallback+"}", "x"), expected: filepath.Join(dirs.Cache(), "x")},
+		{input: filepath.Join("${TEMP:-fallback}", "x"), expected: filepath.Join(dirs.Temp(), "x")},
+		// test the default is applied when the keyword does not resolve
+		{input: filepath.Join("${DATA:-"+fallback+"}", "x"), expected: filepath.Join(fallback, "x")},
+		{input: filepath.Join("${DATA:-fallback}", "x"), expected: filepath.Join(base, "fallback", "x")},
+		{input: filepath.Join("a", "${DATA:-b}", "c"), expected: filepath.Join(base, "a", "b", "c")},
+		{input: "${DATA:-a/b}", expected: filepath.Join(base, "a", "b")},
+		// test the alternative is only applied when the keyword resolves
+		{input: filepath.Join("${CACHE:+alt}", "x"), expected: filepath.Join(base, "alt", "x")},
+		{input: filepath.Join("${DATA:+alt}", "x"), expected: filepath.Join(base, "x")},
+		// test other forms are kept as literal
+		{input: filepath.Join("${DATA:=x}", "y"), expected: filepath.Join(base, "${DATA:=x}", "y")},
+		{input: filepath.Join("${2DATA:-x}", "y"), expected: filepath.Join(base, "${2DATA:-x}", "y")},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, dirs.MakeAbsolute(base, test.input), test.input)
+	}
+
+	// test only a resolved keyword is reported as used
+	_, used := dirs.MakeAbsoluteTrace(base, filepath.Join("${CACHE:-a}", "${DATA:-b}"))
+	assert.Equal(t, []string{"${CACHE:-a}"}, used)
+}
+
+func TestDedup(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	require.Empty(t, dirs.Dedup())
+
+	// test the higher types sharing a path are reported
+	shared := dirs.Cache()
+	for _, d := range []DirType{PersistentTemp, Temp} {
+		dir, e := NewDir(d, appName, WithPath(shared))
+		require.Nil(t, e)
+		dirs.Assign(*dir)
+	}
+	assert.Equal(t, []DirType{Temp, PersistentTemp}, dirs.Dedup())
+	assert.Equal(t, filepath.Join(shared, "x"), dirs.MakeAbsolute("", filepath.Join("$TEMP", "x")))
+
+	// test pruning removes the aliases of the duplicates only
+	assert.Equal(t, []DirType{Temp, PersistentTemp}, dirs.PruneDuplicates())
+	assert.Empty(t, dirs.dir(Temp).Aliases())
+	assert.Equal(t, DefaultAliases(Cache), dirs.dir(Cache).Aliases())
+	assert.Equal(t, shared, dirs.Temp())
+	assert.Equal(t, filepath.Join("$CACHE", "x"), dirs.Parameterize("", filepath.Join(shared, "x")))
+}
+
+func TestGitignoreEntries(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	ws := filepath.Join(filepath.Dir(dirs.Temp()), "workspace")
+	assign := func(dirType DirType, path string) {
+		d, e := NewDir(dirType, appName, WithPath(path))
+		require.Nil(t, e)
+		dirs.Assign(*d)
+	}
+	assign(Workspace, ws)
+	assert.Empty(t, dirs.GitignoreEntries())
+
+	// test the directories nested under the workspace are listed
+	assign(Cache, filepath.Join(ws, ".cache", appName))
+	assign(Temp, filepath.Join(ws, "tmp"))
+	assign(Config, ws)
+	assert.Equal(t, []string{".cache/" + appName + "/", "tmp/"}, dirs.GitignoreEntries())
+
+	// test an unset workspace
+	assert.Empty(t, (&AppDirs{}).GitignoreEntries())
+}
+
 //======================================================================================================================
 // endregion
 //======================================================================================================================
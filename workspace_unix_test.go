@@ -0,0 +1,397 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Test Functions
+//======================================================================================================================
+
+func TestCreateMode(t *testing.T) {
+	// use a permissive umask to ensure the default modes are not masked
+	umask := syscall.Umask(0022)
+	t.Cleanup(func() { syscall.Umask(umask) })
+
+	root := t.TempDir()
+	dirs := &AppDirs{}
+	for _, dirType := range []DirType{Cache, Temp} {
+		d, e := NewDir(dirType, appName, WithPath(filepath.Join(root, dirType.String())))
+		require.Nil(t, e)
+		dirs.Assign(*d)
+	}
+
+	require.Nil(t, dirs.Create(Cache))
+	require.Nil(t, dirs.CreateTemp())
+	require.Nil(t, dirs.RecreateTemp("sub"))
+
+	// test the created directories reflect the default modes
+	expected := map[string]os.FileMode{
+		dirs.Cache():                      0755,
+		dirs.Temp():                       0700,
+		filepath.Join(dirs.Temp(), "sub"): 0700,
+	}
+	for path, mode := range expected {
+		info, e := os.Stat(path)
+		require.Nil(t, e)
+		assert.Equal(t, mode, info.Mode().Perm(), path)
+	}
+
+	// test an explicit mode
+	d, e := NewDir(Cache, appName, WithPath(filepath.Join(root, "explicit")))
+	require.Nil(t, e)
+	dirs.Assign(*d)
+	require.Nil(t, dirs.Create(Cache, WithMode(0750)))
+	info, e := os.Stat(dirs.Cache())
+	require.Nil(t, e)
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+}
+
+func TestCreateExactMode(t *testing.T) {
+	// use a restrictive umask to mask the requested modes
+	umask := syscall.Umask(0077)
+	t.Cleanup(func() { syscall.Umask(umask) })
+
+	root := t.TempDir()
+	dirs := &AppDirs{}
+	for _, dirType := range []DirType{Cache, Temp} {
+		d, e := NewDir(dirType, appName, WithPath(filepath.Join(root, dirType.String())))
+		require.Nil(t, e)
+		dirs.Assign(*d)
+	}
+
+	// test the umask applies by default
+	require.Nil(t, dirs.Create(Cache, WithMode(0755)))
+	info, e := os.Stat(dirs.Cache())
+	require.Nil(t, e)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+
+	// test the exact mode bypasses the umask
+	require.Nil(t, os.Remove(dirs.Cache()))
+	require.Nil(t, dirs.Create(Cache, WithMode(0755), WithExactMode()))
+	info, e = os.Stat(dirs.Cache())
+	require.Nil(t, e)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	require.Nil(t, dirs.CreateTemp(WithMode(0750), WithExactMode()))
+	info, e = os.Stat(dirs.Temp())
+	require.Nil(t, e)
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+}
+
+func TestStalePIDFiles(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	require.Nil(t, dirs.CreateTemp())
+
+	// run a short-lived process to obtain the process id of a dead process
+	cmd := exec.Command("sh", "-c", "exit 0")
+	require.Nil(t, cmd.Run())
+	dead := cmd.Process.Pid
+
+	files := map[string]string{
+		"dead.pid":    strconv.Itoa(dead),
+		"alive.pid":   strconv.Itoa(os.Getpid()),
+		"invalid.pid": "invalid",
+		"dead.lock":   strconv.Itoa(dead),
+	}
+	for name, content := range files {
+		require.Nil(t, os.WriteFile(filepath.Join(dirs.Temp(), name), []byte(content+"\n"), 0644))
+	}
+
+	stale, e := dirs.StalePIDFiles(Temp, ".pid")
+	require.Nil(t, e)
+	assert.Equal(t, []string{filepath.Join(dirs.Temp(), "dead.pid")}, stale)
+
+	// test an unset directory
+	_, e = (&AppDirs{}).StalePIDFiles(Temp, ".pid")
+	assert.EqualError(t, e, "cannot scan temp directory, invalid state")
+}
+
+func TestRemoveTempSymlink(t *testing.T) {
+	// use a custom system temp directory and a forbidden directory outside of it
+	root := t.TempDir()
+	tmp := filepath.Join(root, "tmp")
+	forbidden := filepath.Join(root, "forbidden")
+	require.Nil(t, os.MkdirAll(filepath.Join(forbidden, "sub"), 0755))
+	require.Nil(t, os.Mkdir(tmp, 0755))
+	setEnv(t, "TMPDIR", tmp)
+
+	// link the application's temp directory to the forbidden directory
+	link := filepath.Join(tmp, appName)
+	require.Nil(t, os.Symlink(forbidden, link))
+	d, e := NewDir(Temp, appName, WithPath(link))
+	require.Nil(t, e)
+	dirs := &AppDirs{}
+	dirs.Assign(*d)
+
+	// test the symlinked temp directory is rejected
+	assert.EqualError(t, dirs.RemoveTemp(""), "temp directory is considered unsafe")
+	assert.EqualError(t, dirs.RemoveTemp("sub"), "temp directory is considered unsafe")
+	assert.DirExists(t, filepath.Join(forbidden, "sub"))
+}
+
+func TestSamePhysicalSymlink(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	require.Nil(t, os.MkdirAll(dirs.Cache(), 0700))
+
+	// test a temp directory linking to the cache directory
+	link := filepath.Join(t.TempDir(), "link")
+	require.Nil(t, os.Symlink(dirs.Cache(), link))
+	d, e := NewDir(Temp, appName, WithPath(link))
+	require.Nil(t, e)
+	dirs.Assign(*d)
+
+	same, e := dirs.SamePhysical(Cache, Temp)
+	require.Nil(t, e)
+	assert.True(t, same)
+}
+
+func TestSymlink(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	require.Nil(t, os.MkdirAll(filepath.Join(dirs.Cache(), "entry"), 0700))
+	require.Nil(t, os.WriteFile(filepath.Join(dirs.Cache(), "entry", "file"), []byte("data"), 0600))
+
+	// test the link is created with a relative target
+	require.Nil(t, dirs.Symlink(Temp, "links/entry", Cache, "entry"))
+	link := filepath.Join(dirs.Temp(), "links", "entry")
+	target, e := os.Readlink(link)
+	require.Nil(t, e)
+	assert.False(t, filepath.IsAbs(target))
+	assert.Equal(t, filepath.Join(dirs.Cache(), "entry"), filepath.Join(filepath.Dir(link), target))
+	data, e := os.ReadFile(filepath.Join(link, "file"))
+	require.Nil(t, e)
+	assert.Equal(t, "data", string(data))
+
+	// test paths escaping their directories are rejected
+	assert.NotNil(t, dirs.Symlink(Temp, "../escape", Cache, "entry"))
+	assert.NotNil(t, dirs.Symlink(Temp, "other", Cache, "../entry"))
+	assert.NotNil(t, dirs.Symlink(Temp, "/abs", Cache, "entry"))
+	assert.NotNil(t, dirs.Symlink(Temp, ".", Cache, "entry"))
+	assert.NoFileExists(t, filepath.Join(dirs.Temp(), "other"))
+}
+
+func TestNewAppDirsForUser(t *testing.T) {
+	home := filepath.Join(t.TempDir(), "other")
+	u := &user.User{Uid: "1234", Gid: "1234", Username: "other", HomeDir: home}
+
+	// test the user-specific directories are derived from the user's home directory
+	dirs, e := NewAppDirsForUser(appName, u)
+	require.Nil(t, e)
+	assert.Equal(t, home, dirs.Home())
+	cache, config := filepath.Join(home, ".cache", appName), filepath.Join(home, ".config", appName)
+	if runtime.GOOS == "darwin" {
+		cache = filepath.Join(home, "Library", "Caches", appName)
+		config = filepath.Join(home, "Library", "Application Support", appName)
+	}
+	assert.Equal(t, cache, dirs.Cache())
+	assert.Equal(t, config, dirs.Config())
+	assert.Equal(t, filepath.Join(home, "file"), dirs.MakeAbsolute("", "~/file"))
+	assert.Equal(t, home, dirs.Workspace())
+	assert.Equal(t, filepath.Join(os.TempDir(), appName), dirs.Temp())
+
+	// test the directories are resolved without a home directory of the current process
+	setEnv(t, "HOME", "")
+	require.Nil(t, os.Unsetenv("HOME"))
+	dirs, e = NewAppDirsForUser(appName, u)
+	require.Nil(t, e)
+	assert.Equal(t, home, dirs.Home())
+	assert.Equal(t, cache, dirs.Cache())
+	assert.Equal(t, config, dirs.Config())
+	assert.Equal(t, home, dirs.Workspace())
+	assert.Equal(t, persistentTempPath(runtime.GOOS, appName), dirs.PersistentTemp())
+
+	// test a user without a valid home directory
+	_, e = NewAppDirsForUser(appName, &user.User{Username: "other"})
+	assert.NotNil(t, e)
+	_, e = NewAppDirsForUser(appName, &user.User{Username: "other", HomeDir: "relative"})
+	assert.True(t, errors.Is(e, ErrRelativePath))
+}
+
+func TestRemoveTempRetry(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	require.Nil(t, dirs.CreateTemp())
+
+	// simulate a transient failure on the first two attempts
+	original := removeAll
+	t.Cleanup(func() { removeAll = original })
+	var calls int
+	removeAll = func(path string) error {
+		calls++
+		if calls <= 2 {
+			return &os.PathError{Op: "unlinkat", Path: path, Err: syscall.EBUSY}
+		}
+		return original(path)
+	}
+
+	// test a single attempt fails
+	e := dirs.RemoveTemp("")
+	assert.True(t, errors.Is(e, syscall.EBUSY))
+	assert.DirExists(t, dirs.Temp())
+
+	// test the removal succeeds on retry
+	require.Nil(t, dirs.RemoveTempRetry("", 3, time.Millisecond))
+	assert.Equal(t, 3, calls)
+	assert.NoDirExists(t, dirs.Temp())
+
+	// test other errors are not retried
+	calls = 0
+	removeAll = func(path string) error {
+		calls++
+		return &os.PathError{Op: "unlinkat", Path: path, Err: syscall.EROFS}
+	}
+	assert.NotNil(t, dirs.RemoveTempRetry("", 3, time.Millisecond))
+	assert.Equal(t, 1, calls)
+}
+
+func TestRecreateTempConcurrent(t *testing.T) {
+	dirs := NewTestAppDirs(t, appName)
+	require.Nil(t, dirs.CreateTemp())
+
+	// test concurrent calls on the same subdirectory are serialized
+	const workers = 32
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- dirs.RecreateTemp("x")
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for e := range errs {
+		assert.Nil(t, e)
+	}
+	assert.DirExists(t, filepath.Join(dirs.Temp(), "x"))
+
+	// test concurrent calls on a subdirectory and the entire temp directory are serialized
+	errs = make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		subdir := "x"
+		if i%2 == 0 {
+			subdir = ""
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if e := dirs.RecreateTemp(subdir); e != nil {
+					errs <- e
+					return
+				}
+			}
+			errs <- nil
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for e := range errs {
+		assert.Nil(t, e)
+	}
+	assert.DirExists(t, dirs.Temp())
+}
+
+func TestNamespacedTemp(t *testing.T) {
+	setEnv(t, "TMPDIR", t.TempDir())
+	dirs, e := NewAppDirsNamespaced("VendorX1", "ProductY1")
+	require.Nil(t, e)
+	vendor := filepath.Join(os.TempDir(), "VendorX1")
+	assert.Equal(t, filepath.Join(vendor, "ProductY1"), dirs.Temp())
+
+	// test the vendor directory is created along with the temp directory
+	require.Nil(t, dirs.CreateTemp())
+	assert.DirExists(t, dirs.Temp())
+	require.Nil(t, dirs.RecreateTemp("x"))
+	assert.DirExists(t, filepath.Join(dirs.Temp(), "x"))
+
+	// test the empty vendor directory is removed along with the temp directory
+	require.Nil(t, dirs.RemoveTemp(""))
+	assert.NoDirExists(t, vendor)
+	require.Nil(t, dirs.RecreateTemp(""))
+	assert.DirExists(t, dirs.Temp())
+
+	// test the vendor directory is kept when it contains other products
+	other := filepath.Join(vendor, "Other")
+	require.Nil(t, os.Mkdir(other, 0700))
+	require.Nil(t, dirs.RemoveTemp(""))
+	assert.NoDirExists(t, dirs.Temp())
+	assert.DirExists(t, other)
+}
+
+func TestRemoveTempStaleFallback(t *testing.T) {
+	original, originalFallbacks := mkdir, tempFallbacks
+	t.Cleanup(func() { mkdir, tempFallbacks = original, originalFallbacks })
+
+	// use a system temp directory that is a sibling of the fallback directories
+	root := t.TempDir()
+	system, vetted, custom := filepath.Join(root, "system"), filepath.Join(root, "vetted"), filepath.Join(root, "custom")
+	for _, dir := range []string{system, vetted, custom} {
+		require.Nil(t, os.Mkdir(dir, 0700))
+	}
+	setEnv(t, "TMPDIR", system)
+	tempFallbacks = []string{vetted}
+
+	// test the temp directory created in a default fallback directory can be removed
+	dirs := NewTestAppDirs(t, appName)
+	readOnly := filepath.Dir(dirs.Temp())
+	mkdir = func(path string, mode os.FileMode) error {
+		if filepath.Dir(path) == readOnly {
+			return &os.PathError{Op: "mkdir", Path: path, Err: syscall.EROFS}
+		}
+		return original(path, mode)
+	}
+	require.Nil(t, dirs.CreateTemp())
+	assert.Equal(t, filepath.Join(vetted, "temp"), dirs.Temp())
+	require.Nil(t, dirs.RemoveTemp(""))
+
+	// test the fallback directory is no longer trusted after assigning another temp directory
+	user := filepath.Join(vetted, "user")
+	require.Nil(t, os.Mkdir(user, 0700))
+	temp, e := NewDir(Temp, appName, WithPath(user))
+	require.Nil(t, e)
+	dirs.Assign(*temp)
+	assert.EqualError(t, dirs.RemoveTemp(""), "temp directory is considered unsafe")
+	assert.DirExists(t, user)
+
+	// test a user-specified fallback directory outside of the system's temp directory is not trusted
+	dirs = NewTestAppDirs(t, appName)
+	readOnly = filepath.Dir(dirs.Temp())
+	require.Nil(t, dirs.CreateTemp(WithTempFallback(custom)))
+	assert.Equal(t, filepath.Join(custom, "temp"), dirs.Temp())
+	assert.EqualError(t, dirs.RemoveTemp(""), "temp directory is considered unsafe")
+	assert.DirExists(t, dirs.Temp())
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
@@ -0,0 +1,63 @@
+// Copyright © 2021 Mark Dumay. All rights reserved.
+// Use of this source code is governed by The MIT License (MIT) that can be found in the LICENSE file.
+
+package workspace
+
+//======================================================================================================================
+// region Import Statements
+//======================================================================================================================
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================
+
+//======================================================================================================================
+// region Test Functions
+//======================================================================================================================
+
+func TestHomeAliasWindows(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	input := filepath.Join(dirs.Home(), "test")
+	assert.Equal(t, filepath.Join("%USERPROFILE%", "test"), dirs.Parameterize(dirs.Workspace(), input))
+	assert.Equal(t, input, dirs.MakeAbsolute(dirs.Workspace(), filepath.Join("%USERPROFILE%", "test")))
+}
+
+func TestMakeAbsoluteWindowsEnv(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	// test the temp aliases use the Windows syntax
+	assert.Equal(t, filepath.Join(dirs.Temp(), "x"), dirs.MakeAbsolute(dirs.Workspace(), `%TEMP%\x`))
+	assert.Equal(t, filepath.Join(dirs.Temp(), "x"), dirs.MakeAbsolute(dirs.Workspace(), `%TMP%\x`))
+
+	// test keywords referenced using the Windows syntax
+	assert.Equal(t, filepath.Join(dirs.Cache(), "x"), dirs.MakeAbsolute(dirs.Workspace(), `%CACHE%\x`))
+
+	// test other environment variables using the Windows syntax
+	setEnv(t, "GO_WORKSPACE_DIR", `c:\workspace`)
+	assert.Equal(t, `c:\workspace\x`, dirs.MakeAbsolute(dirs.Workspace(), `%GO_WORKSPACE_DIR%\x`))
+}
+
+func TestMakeAbsoluteSepWindows(t *testing.T) {
+	dirs, e := NewAppDirs(appName)
+	require.Nil(t, e)
+
+	// test a keyworded path generated on Linux
+	expected := filepath.Join(dirs.Cache(), "logs", "app.log")
+	assert.Equal(t, expected, dirs.MakeAbsoluteSep(dirs.Workspace(), "$CACHE/logs/app.log", '/'))
+	assert.Equal(t, `c:\data\file`, dirs.MakeAbsoluteSep(dirs.Workspace(), "c:/data/file", '/'))
+}
+
+//======================================================================================================================
+// endregion
+//======================================================================================================================